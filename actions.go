@@ -0,0 +1,157 @@
+package led
+
+import (
+	"errors"
+	"sort"
+)
+
+// actions maps action names to editor operations. Names follow Readline's
+// function names where there is an equivalent.
+var actions = map[string]func(*Ed, Key){
+	"abort":                             func(e *Ed, k Key) { e.abort() },
+	"accept-line":                       func(e *Ed, k Key) { e.accept() },
+	"backward-char":                     func(e *Ed, k Key) { e.Left() },
+	"backward-delete-char":              func(e *Ed, k Key) { e.Back() },
+	"backward-kill-line":                func(e *Ed, k Key) { e.DeleteToCursor() },
+	"backward-kill-word":                func(e *Ed, k Key) { e.BackKillWord() },
+	"backward-word":                     func(e *Ed, k Key) { e.BackwardWord() },
+	"beginning-of-history":              func(e *Ed, k Key) { e.HistoryFirst(e.Hist.Lines()) },
+	"beginning-of-line":                 func(e *Ed, k Key) { e.Return() },
+	"call-last-kbd-macro":               func(e *Ed, k Key) { e.PlayMacro() },
+	"capitalize-word":                   func(e *Ed, k Key) { e.CapitalizeWord() },
+	"clear-screen":                      func(e *Ed, k Key) { e.ClearScreen() },
+	"describe-key":                      func(e *Ed, k Key) { e.DescribeKey() },
+	"complete":                          func(e *Ed, k Key) { e.AutoComplete(Forw) },
+	"complete-backward":                 func(e *Ed, k Key) { e.AutoComplete(Back) },
+	"delete-char":                       func(e *Ed, k Key) { e.Delete() },
+	"discard-line":                      func(e *Ed, k Key) { e.Discard() },
+	"downcase-word":                     func(e *Ed, k Key) { e.DowncaseWord() },
+	"end-kbd-macro":                     func(e *Ed, k Key) { e.EndMacro() },
+	"end-of-history":                    func(e *Ed, k Key) { e.HistoryLast(e.Hist.Lines()) },
+	"end-of-line":                       func(e *Ed, k Key) { e.End() },
+	"forward-char":                      func(e *Ed, k Key) { e.Right() },
+	"forward-word":                      func(e *Ed, k Key) { e.ForwardWord() },
+	"history-search-backward":           func(e *Ed, k Key) { e.searchHistory(Prefix, Back) },
+	"history-search-forward":            func(e *Ed, k Key) { e.searchHistory(Prefix, Forw) },
+	"history-substring-search-backward": func(e *Ed, k Key) { e.searchHistory(Substr, Back) },
+	"history-substring-search-forward":  func(e *Ed, k Key) { e.searchHistory(Substr, Forw) },
+	"kill-line":                         func(e *Ed, k Key) { e.DeleteFromCursor() },
+	"kill-word":                         func(e *Ed, k Key) { e.KillWord() },
+	"list-bindings":                     func(e *Ed, k Key) { e.ListBindings() },
+	"newline":                           func(e *Ed, k Key) { e.Newline() },
+	"next-history":                      func(e *Ed, k Key) { e.HistoryNext(e.Hist.Lines()) },
+	"previous-history":                  func(e *Ed, k Key) { e.HistoryPrev(e.Hist.Lines()) },
+	"redo":                              func(e *Ed, k Key) { e.Redo() },
+	"self-insert":                       func(e *Ed, k Key) { e.Insert(k.Chars) },
+	"start-kbd-macro":                   func(e *Ed, k Key) { e.StartMacro() },
+	"transpose-chars":                   func(e *Ed, k Key) { e.Transpose() },
+	"transpose-words":                   func(e *Ed, k Key) { e.TransposeWords() },
+	"undo":                              func(e *Ed, k Key) { e.Undo() },
+	"universal-argument":                func(e *Ed, k Key) { e.UniversalArgument() },
+	"unix-line-discard":                 func(e *Ed, k Key) { e.DeleteToCursor() },
+	"unix-word-rubout":                  func(e *Ed, k Key) { e.BackWord() },
+	"upcase-word":                       func(e *Ed, k Key) { e.UpcaseWord() },
+	"vi-backward-delete-char":           viRecord(func(v *vi, e *Ed, k Key) { v.back(e) }),
+	"vi-delete-char":                    viRecord(func(v *vi, e *Ed, k Key) { e.Delete() }),
+	"vi-forward-char":                   viAction(func(v *vi, e *Ed, k Key) { v.right(e, 1) }),
+	"vi-movement-mode":                  viAction((*vi).esc),
+	"vi-self-insert":                    viAction((*vi).chars),
+	"vi-unix-line-discard":              viRecord(func(v *vi, e *Ed, k Key) { e.DeleteToCursor() }),
+	"vi-unix-word-rubout":               viRecord(func(v *vi, e *Ed, k Key) { e.BackWord() }),
+	"yank":                              func(e *Ed, k Key) { e.Yank() },
+	"yank-pop":                          func(e *Ed, k Key) { e.YankPop() },
+}
+
+func init() {
+	// registered here, as setting the editing mode binds actions
+	RegisterAction("emacs-editing-mode", func(e *Ed, k Key) { e.SetEditingMode("emacs") })
+	RegisterAction("vi-editing-mode", func(e *Ed, k Key) { e.SetEditingMode("vi") })
+}
+
+// RegisterAction registers an action with the given name, so it can be bound
+// to keys by name, e.g. using HandleAction, or in config and inputrc files.
+// Registering an existing name replaces the action. Actions should be
+// registered before editors are started.
+func RegisterAction(name string, action func(*Ed, Key)) {
+	actions[name] = action
+}
+
+// Action returns the action with the given name.
+func Action(name string) (func(*Ed, Key), bool) {
+	a, ok := actions[name]
+	return a, ok
+}
+
+// Actions returns the names of all registered actions.
+func Actions() []string {
+	names := []string{}
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HandleAction attaches the action with the given name to a key.
+func (e *Ed) HandleAction(key int, name string) error {
+	a, ok := actions[name]
+	if !ok {
+		return errors.New("unknown action: " + name)
+	}
+	e.Handle(key, a)
+	e.names[key] = name
+	return nil
+}
+
+// HandleSeqAction attaches the action with the given name to a sequence of
+// keys, see HandleSeq.
+func (e *Ed) HandleSeqAction(seq []byte, name string) error {
+	a, ok := actions[name]
+	if !ok {
+		return errors.New("unknown action: " + name)
+	}
+	e.HandleSeq(seq, a)
+	e.seqNames[string(seq)] = name
+	return nil
+}
+
+// accept runs the handler attached to Enter, so keys bound to accept-line
+// behave the same as Enter.
+func (e *Ed) accept() {
+	if h := e.handlers[Enter]; h != nil && e.names[Enter] != "accept-line" {
+		h(e, Keys[Enter])
+	} else {
+		e.Newline()
+	}
+}
+
+func (e *Ed) abort() {
+	e.pending = nil
+	e.args = nil
+	e.Bell()
+}
+
+func (e *Ed) searchHistory(mode int, dir int) {
+	m, matcher := e.HistoryMode, e.HistoryMatcher
+	e.HistoryMode, e.HistoryMatcher = mode, nil
+	e.History(e.Hist.Lines(), dir)
+	e.HistoryMode, e.HistoryMatcher = m, matcher
+}
+
+// viAction returns an action that runs the given func with the vi state. The
+// action does nothing in emacs mode.
+func viAction(h func(*vi, *Ed, Key)) func(*Ed, Key) {
+	return func(e *Ed, k Key) {
+		if v := e.vi; v != nil {
+			h(v, e, k)
+		}
+	}
+}
+
+// viRecord returns a vi action that records keys typed in insert mode, for
+// repeating the change with `.`.
+func viRecord(h func(*vi, *Ed, Key)) func(*Ed, Key) {
+	return viAction(func(v *vi, e *Ed, k Key) {
+		v.record(func(e *Ed, k Key) { h(v, e, k) })(e, k)
+	})
+}
@@ -0,0 +1,49 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHandleAction(t *testing.T) {
+	prompt, term := setup()
+	assert.NoError(t, prompt.HandleAction(CtrlB, "end-of-line"))
+	receive(term, "foo")
+	receive(term, key(CtrlA))
+	receive(term, key(CtrlB))
+	assert.Equal(t, 3, prompt.Pos)
+	assert.Equal(t, "end-of-line", prompt.names[CtrlB])
+
+	prompt.Handle(CtrlB, func(e *Ed, k Key) { e.Return() })
+	assert.Equal(t, "", prompt.names[CtrlB])
+	assert.EqualError(t, prompt.HandleAction(CtrlB, "unknown"), "unknown action: unknown")
+}
+
+func TestRegisterAction(t *testing.T) {
+	RegisterAction("test-shout", func(e *Ed, k Key) { e.Set([]byte("HEY")) })
+	assert.Contains(t, Actions(), "test-shout")
+	_, ok := Action("test-shout")
+	assert.True(t, ok)
+
+	prompt, term := setup()
+	assert.NoError(t, prompt.HandleSeqAction(append(Seq(CtrlX), 's'), "test-shout"))
+	receive(term, key(CtrlX))
+	receive(term, "s")
+	assert.Equal(t, "HEY", prompt.Str())
+}
+
+func TestViActionsInEmacsMode(t *testing.T) {
+	prompt, term := setup()
+	assert.NoError(t, prompt.HandleAction(Esc, "vi-movement-mode"))
+	receive(term, "foo")
+	receive(term, key(Esc))
+	assert.Equal(t, "foo", prompt.Str())
+}
+
+func TestEditingModeSwitch(t *testing.T) {
+	prompt, term := setup()
+	assert.NoError(t, prompt.HandleAction(CtrlT, "vi-editing-mode"))
+	receive(term, key(CtrlT))
+	assert.Equal(t, "vi", prompt.EditingMode())
+	assert.Equal(t, "vi-self-insert", prompt.names[Chars])
+}
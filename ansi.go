@@ -19,6 +19,17 @@ const (
 	Red
 	Green
 	Reset
+	ClearScreen
+	Bell
+	FlashOn
+	FlashOff
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	Reverse
+	ClearDown
+	Dim
 )
 
 // Directions
@@ -40,16 +51,27 @@ func (c Code) Str() string {
 }
 
 var ansi = map[int]Code{
-	Clear:      {Clear, []byte("\x1b[0K"), "<clear>"},
-	ClearLine:  {ClearLine, []byte("\x1b[2K"), "<clear-line>"},
-	ShowCursor: {ShowCursor, []byte("\x1b[?25h"), "<show-crsr>"},
-	HideCursor: {HideCursor, []byte("\x1b[?25l"), "<hide-crsr>"},
-	Newline:    {Newline, []byte("\n"), "<nl>"},
-	Cr:         {Cr, []byte("\r"), "<cr>"},
-	Del:        {Del, []byte("\x7F"), "<del>"},
-	Red:        {Red, []byte("\x1b[0;31m"), "<red>"},
-	Green:      {Green, []byte("\x1b[0;32m"), "<green>"},
-	Reset:      {Reset, []byte("\x1b[0m"), "<reset>"},
+	Clear:       {Clear, []byte("\x1b[0K"), "<clear>"},
+	ClearLine:   {ClearLine, []byte("\x1b[2K"), "<clear-line>"},
+	ShowCursor:  {ShowCursor, []byte("\x1b[?25h"), "<show-crsr>"},
+	HideCursor:  {HideCursor, []byte("\x1b[?25l"), "<hide-crsr>"},
+	Newline:     {Newline, []byte("\n"), "<nl>"},
+	Cr:          {Cr, []byte("\r"), "<cr>"},
+	Del:         {Del, []byte("\x7F"), "<del>"},
+	Red:         {Red, []byte("\x1b[0;31m"), "<red>"},
+	Green:       {Green, []byte("\x1b[0;32m"), "<green>"},
+	Reset:       {Reset, []byte("\x1b[0m"), "<reset>"},
+	ClearScreen: {ClearScreen, []byte("\x1b[H\x1b[2J"), "<clear-screen>"},
+	Bell:        {Bell, []byte("\a"), "<bell>"},
+	FlashOn:     {FlashOn, []byte("\x1b[?5h"), "<flash-on>"},
+	FlashOff:    {FlashOff, []byte("\x1b[?5l"), "<flash-off>"},
+	Yellow:      {Yellow, []byte("\x1b[0;33m"), "<yellow>"},
+	Blue:        {Blue, []byte("\x1b[0;34m"), "<blue>"},
+	Magenta:     {Magenta, []byte("\x1b[0;35m"), "<magenta>"},
+	Cyan:        {Cyan, []byte("\x1b[0;36m"), "<cyan>"},
+	Reverse:     {Reverse, []byte("\x1b[7m"), "<reverse>"},
+	ClearDown:   {ClearDown, []byte("\x1b[0J"), "<clear-down>"},
+	Dim:         {Dim, []byte("\x1b[2m"), "<dim>"},
 }
 
 // Ansi returns the chars for a given ansi code
@@ -62,7 +84,7 @@ func Colored(c int, b []byte) []byte {
 	return concat(ansi[c].Chars, b, ansi[Reset].Chars)
 }
 
-var regCrsr = regexp.MustCompile("\x1b\\[([0-9]+)(C|D)")
+var regCrsr = regexp.MustCompile("\x1b\\[([0-9]+)(A|C|D)")
 var open = []byte("\x1b[")
 var dir = map[int][]byte{
 	Rgt: []byte("C"),
@@ -83,6 +105,12 @@ func MoveCursor(pos int, d int) []byte {
 	return concat(open, p, dir[d])
 }
 
+// CursorUp returns ansi codes for moving the cursor up by the given number of
+// lines.
+func CursorUp(n int) []byte {
+	return concat(open, []byte(fmt.Sprintf("%dA", n)))
+}
+
 // Deansi replaces ansi codes in the given byte array with hints. This is
 // useful for testing.
 func Deansi(b []byte) []byte {
@@ -91,10 +119,13 @@ func Deansi(b []byte) []byte {
 	}
 	for m := regCrsr.FindSubmatch(b); len(m) > 0; m = regCrsr.FindSubmatch(b) {
 		var name string
-		if bytes.Equal(m[2], dir[Rgt]) {
+		switch {
+		case bytes.Equal(m[2], dir[Rgt]):
 			name = "rgt"
-		} else {
+		case bytes.Equal(m[2], dir[Lft]):
 			name = "lft"
+		default:
+			name = "up"
 		}
 		tag := tag(name, m[1])
 		b = bytes.Replace(b, m[0], tag, 1)
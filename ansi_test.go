@@ -16,9 +16,20 @@ func TestDeansi(t *testing.T) {
 	assert.Equal(t, "<red>", deansi(Ansi(Red)))
 	assert.Equal(t, "<green>", deansi(Ansi(Green)))
 	assert.Equal(t, "<reset>", deansi(Ansi(Reset)))
+	assert.Equal(t, "<clear-screen>", deansi(Ansi(ClearScreen)))
+	assert.Equal(t, "<bell>", deansi(Ansi(Bell)))
+	assert.Equal(t, "<flash-on>", deansi(Ansi(FlashOn)))
+	assert.Equal(t, "<flash-off>", deansi(Ansi(FlashOff)))
+	assert.Equal(t, "<yellow>", deansi(Ansi(Yellow)))
+	assert.Equal(t, "<blue>", deansi(Ansi(Blue)))
+	assert.Equal(t, "<magenta>", deansi(Ansi(Magenta)))
+	assert.Equal(t, "<cyan>", deansi(Ansi(Cyan)))
+	assert.Equal(t, "<reverse>", deansi(Ansi(Reverse)))
+	assert.Equal(t, "<clear-down>", deansi(Ansi(ClearDown)))
 	assert.Equal(t, "<cr><rgt-5>", deansi(SetCursor(5)))
 	assert.Equal(t, "<lft-5>", deansi(MoveCursor(5, Lft)))
 	assert.Equal(t, "<rgt-5>", deansi(MoveCursor(5, Rgt)))
+	assert.Equal(t, "<up-5>", deansi(CursorUp(5)))
 }
 
 func deansi(b []byte) string {
@@ -0,0 +1,74 @@
+package led
+
+import (
+	"strconv"
+)
+
+// Arg returns the numeric argument given for the current key, defaulting to
+// 1. A numeric argument is entered by pressing Alt and a digit (or Alt-- for
+// a negative argument), optionally followed by more digits. While it is
+// being entered the prompt shows it as `(arg: 4) `. Handlers can use it to
+// repeat a command, or to make it act backwards if negative.
+func (e *Ed) Arg() int {
+	return e.arg
+}
+
+// UniversalArgument starts a numeric argument of 4, or multiplies the
+// argument being entered by 4, like Emacs' universal-argument.
+func (e *Ed) UniversalArgument() {
+	n := 1
+	if len(e.args) > 0 {
+		n = parseArg(e.args)
+	}
+	e.args = []byte(strconv.Itoa(n * 4))
+	e.Refresh()
+}
+
+// readArg adds the given key to the numeric argument being entered, if it is
+// Alt and a digit, Alt--, or a digit following an argument. Returns true if
+// the key has been consumed.
+func (e *Ed) readArg(k Key) bool {
+	b := k.Chars
+	switch {
+	case len(b) == 2 && b[0] == Keys[Esc].Chars[0] && isDigit(b[1]):
+		e.args = append(e.args, b[1])
+	case len(b) == 2 && b[0] == Keys[Esc].Chars[0] && b[1] == '-':
+		if len(e.args) > 0 {
+			return true
+		}
+		e.args = append(e.args, b[1])
+	case len(e.args) > 0 && k.Code == Chars && len(b) == 1 && isDigit(b[0]):
+		e.args = append(e.args, b[0])
+	default:
+		return false
+	}
+	e.Refresh()
+	return true
+}
+
+// takeArg sets the numeric argument for the current key from the digits
+// entered before, and restores the prompt.
+func (e *Ed) takeArg() {
+	if len(e.args) == 0 {
+		return
+	}
+	e.arg = parseArg(e.args)
+	e.args = nil
+	e.Refresh()
+}
+
+// prompt returns the prompt, or the numeric argument being entered.
+func (e *Ed) prompt() []byte {
+	if len(e.args) > 0 {
+		return []byte("(arg: " + string(e.args) + ") ")
+	}
+	return e.Prompt
+}
+
+func parseArg(b []byte) int {
+	if string(b) == "-" {
+		return -1
+	}
+	n, _ := strconv.Atoi(string(b))
+	return n
+}
@@ -0,0 +1,108 @@
+package led
+
+import (
+	"context"
+	"time"
+)
+
+const spinnerTime = 100 * time.Millisecond
+
+// job represents a func run by Async.
+type job struct {
+	cancel context.CancelFunc
+	frame  int
+}
+
+// Do runs the given func on the editor's event loop, so it can safely
+// update the editor from other goroutines. It does not wait for the func to
+// run, funcs passed after the editor stopped are dropped.
+func (e *Ed) Do(f func(*Ed)) {
+	go func() {
+		select {
+		case e.events <- f:
+		case <-e.done:
+		}
+	}()
+}
+
+// Async runs the given func in a goroutine, e.g. to query a slow index, and
+// shows a spinner after the line while it runs. The func returned by it is
+// run on the editor's event loop, see Do. The context is cancelled, and the
+// result is dropped, if the user presses a key, another func is started, or
+// the AsyncTimeout passes in the meantime.
+func (e *Ed) Async(run func(ctx context.Context) func(*Ed)) {
+	e.cancelJob()
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if e.AsyncTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), e.AsyncTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	j := &job{cancel: cancel}
+	e.job = j
+
+	go func() {
+		done := run(ctx)
+		e.Do(func(e *Ed) {
+			if e.job == j && ctx.Err() == nil {
+				e.cancelJob()
+				if done != nil {
+					done(e)
+				}
+				e.autoSuggest()
+			}
+		})
+	}()
+	go e.spin(ctx, j)
+}
+
+// spin shows the next frame of the spinner while the given job runs, and
+// drops the job once it times out.
+func (e *Ed) spin(ctx context.Context, j *job) {
+	t := time.NewTicker(spinnerTime)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			e.Do(func(e *Ed) {
+				if e.job == j && len(e.Spinner) > 0 {
+					e.indicate(e.SuggestColor, e.Spinner[j.frame%len(e.Spinner)])
+					j.frame++
+				}
+			})
+		case <-ctx.Done():
+			e.Do(func(e *Ed) {
+				if e.job == j {
+					e.cancelJob()
+				}
+			})
+			return
+		}
+	}
+}
+
+// cancelJob cancels the running job, if any, and replaces the spinner with
+// the suggestion, if any.
+func (e *Ed) cancelJob() {
+	j := e.job
+	if j == nil {
+		return
+	}
+	e.job = nil
+	j.cancel()
+	if j.frame > 0 {
+		e.indicate(e.suggestionColor(), string(e.Suggested))
+	}
+}
+
+// indicate shows the given string after the end of the line, in the given
+// color, keeping the cursor in place.
+func (e *Ed) indicate(color int, s string) {
+	e.term.SetCursor(len(e.prompt()) + len(e.Chars))
+	e.clear()
+	if s != "" {
+		e.Write(Colored(color, []byte(s)))
+	}
+	e.SetCursor()
+}
@@ -0,0 +1,102 @@
+package led
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingCompleter returns a completer that completes `git` once release is
+// closed, or sends to cancelled if its context is done first.
+func blockingCompleter(release chan bool, cancelled chan bool) AsyncCompleter {
+	return func(ctx context.Context, line []byte, pos int) ([]Candidate, int, int) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			cancelled <- true
+		}
+		return []Candidate{{Text: "git", Suffix: " "}}, 0, pos
+	}
+}
+
+func TestAsyncComplete(t *testing.T) {
+	release := make(chan bool)
+	prompt, term := setup()
+	prompt.Completer = blockingCompleter(release, nil)
+	receive(term, "g")
+	receive(term, key(Tab))
+	assert.Equal(t, "g", prompt.Str())
+	close(release)
+	await(t, prompt, func(e *Ed) bool { return e.Str() == "git " })
+}
+
+func TestAsyncCompleteCancel(t *testing.T) {
+	cancelled := make(chan bool, 1)
+	prompt, term := setup()
+	prompt.Completer = blockingCompleter(nil, cancelled)
+	receive(term, "g")
+	receive(term, key(Tab))
+	receive(term, "o")
+	assert.True(t, <-cancelled)
+	assert.Equal(t, "go", prompt.Str())
+}
+
+func TestAsyncCompleteTimeout(t *testing.T) {
+	cancelled := make(chan bool, 1)
+	prompt, term := setup()
+	prompt.AsyncTimeout = 20 * time.Millisecond
+	prompt.Completer = blockingCompleter(nil, cancelled)
+	receive(term, "g")
+	receive(term, key(Tab))
+	assert.True(t, <-cancelled)
+	await(t, prompt, func(e *Ed) bool { return e.job == nil })
+	assert.Equal(t, "g", prompt.Str())
+}
+
+func TestAsyncSpinner(t *testing.T) {
+	cancelled := make(chan bool, 1)
+	prompt, term := setup()
+	prompt.Completer = blockingCompleter(nil, cancelled)
+	receive(term, "g")
+	receive(term, key(Tab))
+	await(t, prompt, func(e *Ed) bool {
+		return strings.Contains(string(Deansi([]byte(term.out))), "<clear><green>|<reset>")
+	})
+	reset(term)
+	receive(term, key(Esc))
+	<-cancelled
+	assertOut(t, term, []string{"<cr><rgt-5><clear><cr><rgt-5>"})
+}
+
+// wrappedCompleter forwards to the wrapped completer, like completers
+// combining others do.
+type wrappedCompleter struct {
+	c ContextCompleter
+}
+
+func (w wrappedCompleter) Complete(line []byte, pos int) ([]Candidate, int, int) {
+	return w.c.Complete(line, pos)
+}
+
+func (w wrappedCompleter) CompleteContext(ctx context.Context, line []byte, pos int) ([]Candidate, int, int) {
+	return w.c.CompleteContext(ctx, line, pos)
+}
+
+func TestContextCompleterWrapped(t *testing.T) {
+	release := make(chan bool)
+	prompt, term := setup()
+	prompt.Completer = wrappedCompleter{blockingCompleter(release, nil)}
+	receive(term, "g")
+	receive(term, key(Tab))
+	assert.Equal(t, "g", prompt.Str())
+	close(release)
+	await(t, prompt, func(e *Ed) bool { return e.Str() == "git " })
+}
+
+func TestDo(t *testing.T) {
+	prompt, _ := setup()
+	prompt.Do(func(e *Ed) { e.Insert([]byte("foo")) })
+	await(t, prompt, func(e *Ed) bool { return e.Str() == "foo" })
+}
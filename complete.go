@@ -0,0 +1,246 @@
+package led
+
+import (
+	"context"
+	"strings"
+)
+
+// Completion styles, see AutoComplete
+const (
+	CompleteCycle int = iota
+	CompleteMenu
+	CompleteList
+)
+
+// Candidate represents a completion candidate.
+type Candidate struct {
+	// Text replaces the completed part of the line.
+	Text string
+	// Display is shown in listings instead of the text, if given.
+	Display string
+	// Description is shown next to the candidate in listings, if given.
+	Description string
+	// Suffix is appended to the text when the candidate is inserted, e.g. a
+	// space after a command, or a slash after a directory.
+	Suffix string
+}
+
+// Str returns the text shown for the candidate in listings.
+func (c Candidate) Str() string {
+	if c.Display != "" {
+		return c.Display
+	}
+	return c.Text
+}
+
+// Completer returns candidates for completing the given line at the given
+// cursor position, and the range of the line (from start up to, excluding,
+// end) that is replaced by a candidate.
+type Completer interface {
+	Complete(line []byte, pos int) (cands []Candidate, start int, end int)
+}
+
+// CompleterFunc adapts a func to the Completer interface.
+type CompleterFunc func(line []byte, pos int) ([]Candidate, int, int)
+
+// Complete calls the func.
+func (f CompleterFunc) Complete(line []byte, pos int) ([]Candidate, int, int) {
+	return f(line, pos)
+}
+
+// ContextCompleter is implemented by completers that may take a while, e.g.
+// to query a slow index. AutoComplete runs CompleteContext asynchronously
+// (see Async), passing a context that is cancelled if the user keeps typing,
+// or the AsyncTimeout passes. Completers wrapping other completers should
+// forward it.
+type ContextCompleter interface {
+	Completer
+	CompleteContext(ctx context.Context, line []byte, pos int) ([]Candidate, int, int)
+}
+
+// AsyncCompleter adapts a func, that may take a while, to the
+// ContextCompleter interface.
+type AsyncCompleter func(ctx context.Context, line []byte, pos int) ([]Candidate, int, int)
+
+// Complete calls the func synchronously.
+func (f AsyncCompleter) Complete(line []byte, pos int) ([]Candidate, int, int) {
+	return f(context.Background(), line, pos)
+}
+
+// CompleteContext calls the func.
+func (f AsyncCompleter) CompleteContext(ctx context.Context, line []byte, pos int) ([]Candidate, int, int) {
+	return f(ctx, line, pos)
+}
+
+// MatchingCompleter is implemented by completers that match candidates
+// against the word being completed. AutoComplete completes with the completer
+// returned by WithMatcher for the editor's CompletionMatcher (or
+// IgnoreCaseMatcher if CompletionIgnoreCase is set), which is used unless the
+// completer has a matcher of its own. Completers wrapping other completers
+// should forward it.
+type MatchingCompleter interface {
+	Completer
+	WithMatcher(m Matcher) Completer
+}
+
+// WordCompleter returns a completer that completes the word left of the
+// cursor with the given words, quoting them as needed, and adding a space.
+// Words are matched using the given matcher, by default the editor's
+// CompletionMatcher, or PrefixMatcher (see MatchingCompleter).
+func WordCompleter(words [][]byte, m ...Matcher) Completer {
+	c := &wordCompleter{words: words}
+	if len(m) > 0 {
+		c.matcher = m[0]
+	}
+	return c
+}
+
+type wordCompleter struct {
+	words   [][]byte
+	matcher Matcher
+}
+
+// Complete implements the Completer interface.
+func (c *wordCompleter) Complete(line []byte, pos int) ([]Candidate, int, int) {
+	m := c.matcher
+	if m == nil {
+		m = PrefixMatcher
+	}
+	t := tokenAt(line, pos)
+	cands := []Candidate{}
+	for _, w := range match(m, c.words, []byte(t.Word)) {
+		cand := Candidate{Text: quoteWord(string(w), t.Quote), Suffix: closeQuote(t.Quote)}
+		if cand.Text != string(w) {
+			cand.Display = string(w)
+		}
+		cands = append(cands, cand)
+	}
+	return cands, t.Start, t.End
+}
+
+// WithMatcher implements the MatchingCompleter interface.
+func (c *wordCompleter) WithMatcher(m Matcher) Completer {
+	if c.matcher != nil {
+		return c
+	}
+	return &wordCompleter{words: c.words, matcher: m}
+}
+
+// completion keeps track of the candidates while cycling through them.
+type completion struct {
+	cands []Candidate
+	line  []byte
+	pos   int
+	start int
+	end   int
+	curr  int
+	saved bool
+}
+
+// AutoComplete completes the line at the cursor position using the editor's
+// Completer, according to the CompletionStyle.
+//
+// In CompleteCycle style (the default) repeated calls cycle through the
+// candidates in the given direction, each replacing the previous one. In
+// CompleteMenu style the candidates are shown in columns below the line, with
+// the selected candidate highlighted. Tab, Shift-Tab, and the arrow keys move
+// the selection, Enter accepts it, Esc restores the original line, any other
+// key accepts the selection and is handled as usual.
+//
+// In CompleteList style, like in Bash, the longest common prefix of all
+// candidates is inserted, and repeated calls list all candidates below the
+// line. If there are at least CompletionQueryItems candidates the user is
+// asked for confirmation first, long listings are shown a page at a time.
+//
+// A ContextCompleter is run in the background, showing a spinner, and the
+// candidates are shown once it returns, unless the user pressed a key in the
+// meantime.
+func (e *Ed) AutoComplete(dir int) {
+	if e.Completer == nil {
+		return
+	}
+	defer e.mark(cmdComplete)
+	if e.comp != nil && e.follows(cmdComplete) {
+		if e.CompletionStyle == CompleteList {
+			e.listCandidates()
+		} else {
+			e.selectCandidate(step(dir))
+		}
+		return
+	}
+
+	line, pos := dup(e.Chars), e.Pos
+	c := e.Completer
+	if mc, ok := c.(MatchingCompleter); ok && e.completionMatcher() != nil {
+		c = mc.WithMatcher(e.completionMatcher())
+	}
+	if cc, ok := c.(ContextCompleter); ok {
+		e.Async(func(ctx context.Context) func(*Ed) {
+			cands, start, end := cc.CompleteContext(ctx, line, pos)
+			return func(e *Ed) { e.complete(cands, start, end, dir) }
+		})
+		return
+	}
+	cands, start, end := c.Complete(line, pos)
+	e.complete(cands, start, end, dir)
+}
+
+// complete starts completing with the given candidates, or rings the bell if
+// there are none, or the range to replace is not within the line.
+func (e *Ed) complete(cands []Candidate, start int, end int, dir int) {
+	if len(cands) == 0 || start < 0 || start > end || end > len(e.Chars) {
+		e.comp = nil
+		e.Bell()
+		return
+	}
+	e.comp = &completion{cands: cands, line: dup(e.Chars), pos: e.Pos, start: start, end: end, curr: -1}
+	switch {
+	case len(cands) == 1:
+		e.selectCandidate(1)
+		e.comp = nil
+	case e.CompletionStyle == CompleteMenu:
+		e.openMenu(dir)
+	case e.CompletionStyle == CompleteList:
+		e.insertPrefix()
+	default:
+		e.selectCandidate(step(dir))
+	}
+}
+
+// selectCandidate moves the selection by the given number of candidates,
+// wrapping around at both ends, and inserts the selected candidate.
+func (e *Ed) selectCandidate(i int) {
+	c := e.comp
+	n := len(c.cands)
+	if c.curr < 0 && i < 0 {
+		c.curr = 0
+	}
+	c.curr = ((c.curr+i)%n + n) % n
+	e.insertCandidate(c.cands[c.curr])
+}
+
+// insertCandidate replaces the completed part of the line with the given
+// candidate, skipping a trailing space in its suffix if the rest of the line
+// starts with a blank. The line before completing is saved for undo once.
+func (e *Ed) insertCandidate(cand Candidate) {
+	c := e.comp
+	if !c.saved {
+		e.save()
+		c.saved = true
+	}
+	rest := c.line[c.end:]
+	suffix := cand.Suffix
+	if len(rest) > 0 && isBlank(rest[0]) {
+		suffix = strings.TrimSuffix(suffix, " ")
+	}
+	b := []byte(cand.Text + suffix)
+	e.redraw(concat(dup(c.line[:c.start]), b, rest), c.start+len(b))
+}
+
+// step returns the offset for moving one item in the given direction.
+func step(dir int) int {
+	if dir == Back {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,118 @@
+package led
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// argCompleter completes commands as the first word, and their arguments
+// otherwise.
+var argCompleter = CompleterFunc(func(line []byte, pos int) ([]Candidate, int, int) {
+	start := bytes.LastIndexByte(line[:pos], ' ') + 1
+	if start == 0 {
+		return []Candidate{{Text: "git", Suffix: " "}, {Text: "go", Suffix: " "}}, start, pos
+	}
+	return []Candidate{{Text: "src", Suffix: "/"}, {Text: "docs", Suffix: "/", Description: "documentation"}}, start, pos
+})
+
+func TestAutoComplete(t *testing.T) {
+	prompt, term := setup()
+	prompt.Completer = argCompleter
+	receive(term, "g")
+	receive(term, key(Tab))
+	assert.Equal(t, "git ", prompt.Str())
+	receive(term, key(Tab))
+	assert.Equal(t, "go ", prompt.Str())
+	receive(term, key(Tab))
+	assert.Equal(t, "git ", prompt.Str())
+	receive(term, key(ShiftTab))
+	assert.Equal(t, "go ", prompt.Str())
+	assert.Equal(t, 3, prompt.Pos)
+
+	receive(term, "s")
+	receive(term, key(Tab))
+	assert.Equal(t, "go src/", prompt.Str())
+	receive(term, key(Tab))
+	assert.Equal(t, "go docs/", prompt.Str())
+}
+
+func TestAutoCompleteRange(t *testing.T) {
+	prompt, _ := setup()
+	prompt.Completer = CompleterFunc(func(line []byte, pos int) ([]Candidate, int, int) {
+		return []Candidate{{Text: "bar"}}, 4, 6
+	})
+	prompt.Set([]byte("foo ba baz"))
+	prompt.SetCursor(6)
+	prompt.AutoComplete(Forw)
+	assert.Equal(t, "foo bar baz", prompt.Str())
+	assert.Equal(t, 7, prompt.Pos)
+	prompt.Undo()
+	assert.Equal(t, "foo ba baz", prompt.Str())
+}
+
+func TestAutoCompleteInvalidRange(t *testing.T) {
+	for _, r := range [][2]int{{-1, 2}, {2, 1}, {1, 4}} {
+		prompt, term := setup()
+		prompt.Completer = CompleterFunc(func(line []byte, pos int) ([]Candidate, int, int) {
+			return []Candidate{{Text: "bar"}}, r[0], r[1]
+		})
+		receive(term, "foo")
+		reset(term)
+		receive(term, key(Tab))
+		assert.Equal(t, "foo", prompt.Str())
+		assertOut(t, term, []string{"<bell>"})
+	}
+}
+
+func TestAutoCompleteNoCandidates(t *testing.T) {
+	prompt, term := setup()
+	prompt.Completer = WordCompleter([][]byte{[]byte("foo")})
+	receive(term, "x")
+	reset(term)
+	receive(term, key(Tab))
+	assert.Equal(t, "x", prompt.Str())
+	assertOut(t, term, []string{"<bell>"})
+}
+
+func TestWordCompleter(t *testing.T) {
+	c := WordCompleter([][]byte{[]byte("repo"), []byte("repos"), []byte("user")})
+	cands, start, end := c.Complete([]byte("show re"), 7)
+	assert.Equal(t, []Candidate{{Text: "repo", Suffix: " "}, {Text: "repos", Suffix: " "}}, cands)
+	assert.Equal(t, 5, start)
+	assert.Equal(t, 7, end)
+}
+
+func TestCandidateStr(t *testing.T) {
+	assert.Equal(t, "src", Candidate{Text: "src"}.Str())
+	assert.Equal(t, "src/", Candidate{Text: "src", Display: "src/"}.Str())
+}
+
+func TestAutoCompleteMidLine(t *testing.T) {
+	prompt, term := setup()
+	prompt.Completer = WordCompleter(bytesOf("commit", "checkout"))
+	receive(term, "git co foo")
+	prompt.SetCursor(6)
+	receive(term, key(Tab))
+	assert.Equal(t, "git commit foo", prompt.Str())
+	assert.Equal(t, 10, prompt.Pos)
+
+	prompt, term = setup()
+	prompt.Completer = WordCompleter(bytesOf("commit", "checkout"))
+	receive(term, "git chxx foo")
+	prompt.SetCursor(6)
+	receive(term, key(Tab))
+	assert.Equal(t, "git checkout foo", prompt.Str())
+}
+
+func TestCompleteNextMidLine(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "git c foo")
+	prompt.SetCursor(5)
+	prompt.CompleteNext(bytesOf("commit", "checkout"))
+	assert.Equal(t, "git commit foo", prompt.Str())
+	assert.Equal(t, 10, prompt.Pos)
+	prompt.CompleteNext(bytesOf("commit", "checkout"))
+	assert.Equal(t, "git checkout foo", prompt.Str())
+	assert.Equal(t, 12, prompt.Pos)
+}
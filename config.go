@@ -0,0 +1,489 @@
+package led
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config represents an editor configuration, as read from a JSON file, e.g.:
+//
+//	{
+//	  "editing_mode": "emacs",
+//	  "prompt": "$ ",
+//	  "bell_style": "visible",
+//	  "auto_suggest": true,
+//	  "colors": { "prompt": "blue", "suggest": "green", "autosuggest": "dim", "reject": "red" },
+//	  "keys": { "Ctrl-A": "beginning-of-line", "Ctrl-X Ctrl-R": "reload-config" },
+//	  "history": { "file": "~/.app_history", "mode": "prefix", "ignore_dups": true },
+//	  "completion": { "style": "menu", "matcher": "fuzzy" }
+//	}
+//
+// or a TOML file with the same settings, e.g.:
+//
+//	editing_mode = "emacs"
+//	prompt = "$ "
+//
+//	[keys]
+//	Ctrl-A = "beginning-of-line"
+//	"Ctrl-X Ctrl-R" = "reload-config"
+//
+//	[history]
+//	file = "~/.app_history"
+//	ignore = ["ls", "exit"]
+//
+// Keys are given as key names (see Keys) separated by spaces, and bound to
+// the action names used in inputrc files (see Actions), or to a named macro
+// as `macro:<name>` (see NameMacro and LoadMacros). Settings that are
+// omitted are left unchanged. Matchers are one of "prefix", "ignore-case",
+// "smart-case", "substring", "fuzzy", and "regexp", see Matcher.
+type Config struct {
+	EditingMode string            `json:"editing_mode"`
+	Prompt      *string           `json:"prompt"`
+	BellStyle   string            `json:"bell_style"`
+	AutoSuggest *bool             `json:"auto_suggest"`
+	Colors      map[string]string `json:"colors"`
+	Keys        map[string]string `json:"keys"`
+	History     *HistoryConfig    `json:"history"`
+	Completion  *CompletionConfig `json:"completion"`
+}
+
+// HistoryConfig represents the history settings in a Config, see History.
+type HistoryConfig struct {
+	File        string   `json:"file"`
+	Mode        string   `json:"mode"`
+	Matcher     string   `json:"matcher"`
+	IgnoreSpace *bool    `json:"ignore_space"`
+	IgnoreDups  *bool    `json:"ignore_dups"`
+	EraseDups   *bool    `json:"erase_dups"`
+	Ignore      []string `json:"ignore"`
+}
+
+// CompletionConfig represents the completion settings in a Config. The style
+// is one of "cycle", "menu", and "list", see AutoComplete.
+type CompletionConfig struct {
+	Style      string `json:"style"`
+	IgnoreCase *bool  `json:"ignore_case"`
+	Matcher    string `json:"matcher"`
+	QueryItems *int   `json:"query_items"`
+}
+
+func init() {
+	RegisterAction("reload-config", func(e *Ed, k Key) { e.report(e.ReloadConfig()) })
+}
+
+var colors = map[string]int{
+	"default": Reset,
+	"dim":     Dim,
+	"red":     Red,
+	"green":   Green,
+	"yellow":  Yellow,
+	"blue":    Blue,
+	"magenta": Magenta,
+	"cyan":    Cyan,
+}
+
+var completionStyles = map[string]int{
+	"cycle": CompleteCycle,
+	"menu":  CompleteMenu,
+	"list":  CompleteList,
+}
+
+var historyModes = map[string]int{
+	"first-word": Hist,
+	"prefix":     Prefix,
+	"substring":  Substr,
+}
+
+// ParseConfig parses and validates the given JSON config. Errors refer to the
+// line the problem was found on, all invalid settings are reported at once.
+func ParseConfig(data []byte) (*Config, error) {
+	return decodeConfig(data, jsonLines(data))
+}
+
+// ParseTOMLConfig parses and validates the given config in TOML format, with
+// the same settings as in JSON, see ParseConfig.
+func ParseTOMLConfig(data []byte) (*Config, error) {
+	values, lines, err := parseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(b, lines)
+}
+
+// decodeConfig decodes and validates the given JSON config, using the given
+// lines of the settings for errors.
+func decodeConfig(data []byte, lines map[string]int) (*Config, error) {
+	c := &Config{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(c); err != nil {
+		if e, ok := err.(*json.SyntaxError); ok {
+			return nil, fmt.Errorf("line %d: %v", lineOf(data, int(e.Offset)), err)
+		}
+		return nil, configError(lines, err)
+	}
+	if err := c.validate(lines); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadConfig reads the config from the given file, and applies it to the
+// editor. Files ending in `.toml` are read as TOML, others as JSON. The file
+// is remembered, so the config can be reloaded using
+// ReloadConfig, or the action reload-config.
+func (e *Ed) LoadConfig(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	parse := ParseConfig
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		parse = ParseTOMLConfig
+	}
+	c, err := parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	e.config = path
+	return e.Configure(c)
+}
+
+// ReloadConfig reads the config file loaded last again, and applies it.
+func (e *Ed) ReloadConfig() error {
+	if e.config == "" {
+		return errors.New("no config loaded")
+	}
+	return e.LoadConfig(e.config)
+}
+
+// Configure validates the given config, and applies it to the editor if it
+// is valid. Setting the editing mode replaces all handlers, see
+// SetEditingMode.
+func (e *Ed) Configure(c *Config) error {
+	if err := c.validate(nil); err != nil {
+		return err
+	}
+	if c.EditingMode != "" {
+		if err := e.SetEditingMode(c.EditingMode); err != nil {
+			return err
+		}
+	}
+	if c.Prompt != nil {
+		e.Prompt = []byte(*c.Prompt)
+	}
+	if c.BellStyle != "" {
+		e.BellStyle = c.BellStyle
+	}
+	if c.AutoSuggest != nil {
+		e.AutoSuggest = *c.AutoSuggest
+	}
+	for name, color := range c.Colors {
+		switch name {
+		case "prompt":
+			e.PromptColor = colors[color]
+		case "suggest":
+			e.SuggestColor = colors[color]
+		case "autosuggest":
+			e.AutoSuggestColor = colors[color]
+		case "reject":
+			e.RejectColor = colors[color]
+		}
+	}
+	for _, spec := range sortedKeys(c.Keys) {
+		seq, err := parseKeySpec(spec)
+		if err != nil {
+			return err
+		}
+		if err := e.bindAction(seq, c.Keys[spec]); err != nil {
+			return err
+		}
+	}
+	if h := c.History; h != nil {
+		if file := expandHome(h.File); file != "" && file != e.Hist.File {
+			e.Hist = NewHistory(file)
+		}
+		if h.Mode != "" {
+			e.HistoryMode = historyModes[h.Mode]
+		}
+		if h.Matcher != "" {
+			e.HistoryMatcher = matchers[h.Matcher]()
+		}
+		if h.IgnoreSpace != nil {
+			e.Hist.IgnoreSpace = *h.IgnoreSpace
+		}
+		if h.IgnoreDups != nil {
+			e.Hist.IgnoreDups = *h.IgnoreDups
+		}
+		if h.EraseDups != nil {
+			e.Hist.EraseDups = *h.EraseDups
+		}
+		if h.Ignore != nil {
+			e.Hist.Ignore = h.Ignore
+		}
+	}
+	if c := c.Completion; c != nil {
+		if c.Style != "" {
+			e.CompletionStyle = completionStyles[c.Style]
+		}
+		if c.QueryItems != nil {
+			e.CompletionQueryItems = *c.QueryItems
+		}
+		if c.Matcher != "" {
+			e.CompletionMatcher = matchers[c.Matcher]()
+		}
+		if c.IgnoreCase != nil {
+			e.CompletionIgnoreCase = *c.IgnoreCase
+		}
+	}
+	e.Refresh()
+	return nil
+}
+
+// validate checks the config. Errors refer to the lines of the settings by
+// their path, if given, see jsonLines.
+func (c *Config) validate(lines map[string]int) error {
+	var errs []string
+	invalid := func(path string, format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		if line := lineOfPath(lines, path); line > 0 {
+			msg = fmt.Sprintf("line %d: %s", line, msg)
+		}
+		errs = append(errs, msg)
+	}
+
+	if m := c.EditingMode; m != "" && m != "emacs" && m != "vi" {
+		invalid("editing_mode", "unknown editing mode: %s", m)
+	}
+	if s := c.BellStyle; s != "" && s != "none" && s != "audible" && s != "visible" {
+		invalid("bell_style", "invalid bell style: %s", s)
+	}
+	for _, name := range sortedKeys(c.Colors) {
+		if name != "prompt" && name != "suggest" && name != "autosuggest" && name != "reject" {
+			invalid("colors."+name, "unknown color setting: %s", name)
+		}
+		if _, ok := colors[c.Colors[name]]; !ok {
+			invalid("colors."+name, "unknown color: %s", c.Colors[name])
+		}
+	}
+	for _, spec := range sortedKeys(c.Keys) {
+		if _, err := parseKeySpec(spec); err != nil {
+			invalid("keys."+spec, "%v", err)
+		}
+		if _, ok := actions[c.Keys[spec]]; !ok && !isMacroName(c.Keys[spec]) {
+			invalid("keys."+spec, "unknown action: %s", c.Keys[spec])
+		}
+	}
+	if h := c.History; h != nil {
+		if _, ok := historyModes[h.Mode]; !ok && h.Mode != "" {
+			invalid("history.mode", "unknown history mode: %s", h.Mode)
+		}
+		if _, ok := matchers[h.Matcher]; !ok && h.Matcher != "" {
+			invalid("history.matcher", "unknown matcher: %s", h.Matcher)
+		}
+		for i, p := range h.Ignore {
+			if err := (&History{}).SetIgnore(p); err != nil {
+				invalid("history.ignore."+strconv.Itoa(i), "%v", err)
+			}
+		}
+	}
+
+	if c := c.Completion; c != nil {
+		if _, ok := completionStyles[c.Style]; !ok && c.Style != "" {
+			invalid("completion.style", "unknown completion style: %s", c.Style)
+		}
+		if _, ok := matchers[c.Matcher]; !ok && c.Matcher != "" {
+			invalid("completion.matcher", "unknown matcher: %s", c.Matcher)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return lineNum(errs[i]) < lineNum(errs[j]) })
+	return errors.New(strings.Join(errs, "\n"))
+}
+
+// isMacroName returns whether the given action name refers to a macro.
+func isMacroName(name string) bool {
+	return strings.HasPrefix(name, macroPrefix) && len(name) > len(macroPrefix)
+}
+
+// parseKeySpec parses a space separated list of key names, such as
+// `Ctrl-X Ctrl-U`, into the chars of the key sequence. Keys are matched by
+// their names (see Keys), other than that `Ctrl-` and `Alt-` followed by a
+// char, `Space`, and single chars are supported.
+func parseKeySpec(spec string) ([]byte, error) {
+	b := []byte{}
+	for _, name := range strings.Fields(spec) {
+		c, err := parseKey(name)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, c...)
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key")
+	}
+	return b, nil
+}
+
+func parseKey(name string) ([]byte, error) {
+	for _, k := range Keys {
+		if strings.EqualFold(k.Name, name) {
+			return k.Chars, nil
+		}
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case lower == "space":
+		return space, nil
+	case len(name) == 1:
+		return []byte(name), nil
+	case strings.HasPrefix(lower, "ctrl-") && len(name) == 6:
+		return []byte{control(lower[5])}, nil
+	case strings.HasPrefix(lower, "alt-") && len(name) == 5:
+		return []byte{0x1b, lower[4]}, nil
+	}
+	return nil, errors.New("unknown key: " + name)
+}
+
+// configError adds the line number to type errors and unknown settings.
+func configError(lines map[string]int, err error) error {
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		return fmt.Errorf("line %d: %v", lineOfPath(lines, e.Field), err)
+	}
+	if strings.HasPrefix(err.Error(), "json: unknown field ") {
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return fmt.Errorf("line %d: unknown setting: %s", unknownLine(lines, field), field)
+	}
+	return err
+}
+
+// jsonLines returns the line numbers of the settings in the given JSON
+// document by their path, e.g. `history.mode`, `keys.Ctrl-A`, or
+// `history.ignore.0` for array elements. Object members are located by their
+// name, array elements by their value.
+func jsonLines(data []byte) map[string]int {
+	type frame struct {
+		path   string
+		obj    bool
+		member string
+		name   bool
+		n      int
+	}
+	lines := map[string]int{}
+	stack := []*frame{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := int(d.InputOffset())
+		t, err := d.Token()
+		if err != nil {
+			return lines
+		}
+		for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) != -1 {
+			offset++
+		}
+		line := lineOf(data, offset)
+
+		if t == json.Delim('}') || t == json.Delim(']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		path := ""
+		if len(stack) > 0 {
+			f := stack[len(stack)-1]
+			switch {
+			case f.obj && f.name:
+				f.member, f.name = joinPath(f.path, t.(string)), false
+				lines[f.member] = line
+				continue
+			case f.obj:
+				path, f.name = f.member, true
+			default:
+				path = joinPath(f.path, strconv.Itoa(f.n))
+				lines[path] = line
+				f.n++
+			}
+		}
+		switch t {
+		case json.Delim('{'):
+			stack = append(stack, &frame{path: path, obj: true, name: true})
+		case json.Delim('['):
+			stack = append(stack, &frame{path: path})
+		}
+	}
+}
+
+// joinPath appends the given name to the path of a setting.
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// lineOfPath returns the line of the setting with the given path, or of the
+// closest enclosing one.
+func lineOfPath(lines map[string]int, path string) int {
+	for {
+		if line, ok := lines[path]; ok {
+			return line
+		}
+		i := strings.LastIndexByte(path, '.')
+		if i == -1 {
+			return 0
+		}
+		path = path[:i]
+	}
+}
+
+// unknownLine returns the first line of a setting with the given name,
+// skipping names of keys and colors.
+func unknownLine(lines map[string]int, name string) int {
+	line := 0
+	for path, l := range lines {
+		if path != name && !strings.HasSuffix(path, "."+name) {
+			continue
+		}
+		parent := strings.TrimSuffix(strings.TrimSuffix(path, name), ".")
+		if parent != "keys" && parent != "colors" && (line == 0 || l < line) {
+			line = l
+		}
+	}
+	return line
+}
+
+// lineOf returns the line number of the given offset.
+func lineOf(data []byte, offset int) int {
+	if offset < 0 {
+		return 0
+	}
+	return bytes.Count(data[:min(offset, len(data))], newline) + 1
+}
+
+func lineNum(err string) int {
+	n := 0
+	fmt.Sscanf(err, "line %d:", &n)
+	return n
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
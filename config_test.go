@@ -0,0 +1,236 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigure(t *testing.T) {
+	prompt, term := setup()
+	c, err := ParseConfig([]byte(`{
+  "prompt": "$ ",
+  "bell_style": "none",
+  "auto_suggest": true,
+  "colors": { "prompt": "blue", "suggest": "cyan", "autosuggest": "yellow" },
+  "keys": { "Ctrl-B": "end-of-line", "Ctrl-X a": "beginning-of-line" },
+  "history": { "mode": "prefix", "ignore_dups": true },
+  "completion": { "ignore_case": true, "matcher": "fuzzy" }
+}`))
+	assert.NoError(t, err)
+	reset(term)
+	assert.NoError(t, prompt.Configure(c))
+	assertOut(t, term, []string{"<cr><clear><blue>$ <reset><cr><rgt-2>"})
+
+	assert.Equal(t, "none", prompt.BellStyle)
+	assert.True(t, prompt.AutoSuggest)
+	assert.Equal(t, Cyan, prompt.SuggestColor)
+	assert.Equal(t, Yellow, prompt.AutoSuggestColor)
+	assert.Equal(t, Red, prompt.RejectColor)
+	assert.Equal(t, Prefix, prompt.HistoryMode)
+	assert.True(t, prompt.Hist.IgnoreDups)
+	assert.True(t, prompt.CompletionIgnoreCase)
+	assert.NotNil(t, prompt.CompletionMatcher)
+
+	receive(term, "foo")
+	receive(term, key(CtrlX))
+	receive(term, "a")
+	assert.Equal(t, 0, prompt.Pos)
+	receive(term, key(CtrlB))
+	assert.Equal(t, 3, prompt.Pos)
+}
+
+func TestConfigureKeepsOmitted(t *testing.T) {
+	prompt, _ := setup()
+	prompt.Hist.IgnoreSpace = true
+	prompt.Hist.EraseDups = true
+	prompt.Hist.Ignore = []string{"ls"}
+	prompt.CompletionIgnoreCase = true
+	c, err := ParseConfig([]byte(`{ "history": { "mode": "prefix" }, "completion": { "style": "menu" } }`))
+	assert.NoError(t, err)
+	assert.NoError(t, prompt.Configure(c))
+	assert.True(t, prompt.Hist.IgnoreSpace)
+	assert.True(t, prompt.Hist.EraseDups)
+	assert.Equal(t, []string{"ls"}, prompt.Hist.Ignore)
+	assert.True(t, prompt.CompletionIgnoreCase)
+
+	c, err = ParseConfig([]byte(`{ "history": { "erase_dups": false } }`))
+	assert.NoError(t, err)
+	assert.NoError(t, prompt.Configure(c))
+	assert.True(t, prompt.Hist.IgnoreSpace)
+	assert.False(t, prompt.Hist.EraseDups)
+}
+
+func TestConfigureInvalid(t *testing.T) {
+	prompt, _ := setup()
+	prompt.Prompt = []byte("> ")
+	prompt.SuggestColor = Green
+	p := "$ "
+	err := prompt.Configure(&Config{
+		Prompt:     &p,
+		Colors:     map[string]string{"suggest": "pink"},
+		Keys:       map[string]string{"Hyper-X": "end-of-line"},
+		History:    &HistoryConfig{Matcher: "exact"},
+		Completion: &CompletionConfig{Style: "grid"},
+	})
+	assert.EqualError(t, err, "unknown color: pink\nunknown key: Hyper-X\nunknown matcher: exact\nunknown completion style: grid")
+	assert.Equal(t, "> ", string(prompt.Prompt))
+	assert.Equal(t, Green, prompt.SuggestColor)
+}
+
+func TestConfigEditingMode(t *testing.T) {
+	prompt, _ := setup()
+	c, err := ParseConfig([]byte(`{ "editing_mode": "vi" }`))
+	assert.NoError(t, err)
+	assert.NoError(t, prompt.Configure(c))
+	assert.Equal(t, "vi", prompt.EditingMode())
+}
+
+func TestConfigErrors(t *testing.T) {
+	_, err := ParseConfig([]byte(`{
+  "keys": {
+    "Ctrl-A": "beginning-of-line",
+    "Ctrl-B": "unknown-action",
+    "Hyper-X": "end-of-line"
+  },
+  "colors": { "prompt": "pink" },
+  "history": { "matcher": "exact" }
+}`))
+	assert.EqualError(t, err, "line 4: unknown action: unknown-action\nline 5: unknown key: Hyper-X\nline 7: unknown color: pink\nline 8: unknown matcher: exact")
+
+	_, err = ParseConfig([]byte(`{
+  "colors": { "prompt": "pink" },
+  "keys": { "Ctrl-A": "pink", "Ctrl-B": "pink" },
+  "history": {
+    "ignore": ["ls",
+      "[z-a]"]
+  },
+  "completion": { "matcher": "pink" }
+}`))
+	assert.EqualError(t, err, "line 2: unknown color: pink\nline 3: unknown action: pink\nline 3: unknown action: pink\nline 6: invalid ignore pattern: [z-a]\nline 8: unknown matcher: pink")
+
+	_, err = ParseConfig([]byte("{\n  \"prompt\": \"$ \",\n  \"promt\": \"$ \"\n}"))
+	assert.EqualError(t, err, "line 3: unknown setting: promt")
+
+	_, err = ParseConfig([]byte("{\n  \"prompt\": \"$ \"\n  \"keys\": {}\n}"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3: ")
+
+	_, err = ParseConfig([]byte("{\n  \"history\": { \"ignore\": [\"[z-a]\"] }\n}"))
+	assert.EqualError(t, err, "line 2: invalid ignore pattern: [z-a]")
+
+	_, err = ParseConfig([]byte("{\n  \"bell_style\": true\n}"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2: ")
+}
+
+func TestConfigTOML(t *testing.T) {
+	prompt, _ := setup()
+	c, err := ParseTOMLConfig([]byte(`# led config
+prompt = "$ "
+auto_suggest = true
+colors.suggest = 'cyan'
+
+[keys]
+Ctrl-B = "end-of-line" # comment
+"Ctrl-X a" = "beginning-of-line"
+
+[history]
+mode = "prefix"
+ignore_dups = true
+ignore = [
+  "ls",
+  "exit",
+]
+
+[completion]
+query_items = 1_000
+`))
+	assert.NoError(t, err)
+	assert.NoError(t, prompt.Configure(c))
+	assert.Equal(t, "$ ", string(prompt.Prompt))
+	assert.True(t, prompt.AutoSuggest)
+	assert.Equal(t, Cyan, prompt.SuggestColor)
+	assert.Equal(t, Prefix, prompt.HistoryMode)
+	assert.True(t, prompt.Hist.IgnoreDups)
+	assert.Equal(t, []string{"ls", "exit"}, prompt.Hist.Ignore)
+	assert.Equal(t, 1000, prompt.CompletionQueryItems)
+	assert.Equal(t, "end-of-line", prompt.names[CtrlB])
+}
+
+func TestConfigTOMLErrors(t *testing.T) {
+	_, err := ParseTOMLConfig([]byte(`[keys]
+Ctrl-A = "beginning-of-line"
+Ctrl-B = "unknown-action"
+
+[history]
+ignore = ["ls",
+  "[z-a]"]
+`))
+	assert.EqualError(t, err, "line 3: unknown action: unknown-action\nline 7: invalid ignore pattern: [z-a]")
+
+	_, err = ParseTOMLConfig([]byte("prompt = \"$ \"\npromt = \"$ \"\n"))
+	assert.EqualError(t, err, "line 2: unknown setting: promt")
+
+	_, err = ParseTOMLConfig([]byte("[history]\nignore_dups = \"yes\"\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2: ")
+
+	_, err = ParseTOMLConfig([]byte("prompt = \"$ \"\nprompt = \"> \"\n"))
+	assert.EqualError(t, err, "line 2: duplicate key: prompt")
+
+	_, err = ParseTOMLConfig([]byte("[history]\nmode = prefix\n"))
+	assert.EqualError(t, err, "line 2: invalid value: prefix")
+
+	_, err = ParseTOMLConfig([]byte("prompt = \"$ \n"))
+	assert.EqualError(t, err, "line 1: unterminated string")
+
+	_, err = ParseTOMLConfig([]byte("prompt = \"$ \\\n"))
+	assert.EqualError(t, err, "line 1: unterminated string")
+}
+
+func TestConfigTOMLEscapes(t *testing.T) {
+	v, _, err := parseTOML([]byte(`a = "\t\"\\\u00e9\U0001F600"
+b = 'C:\x\u12'
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "\t\"\\\u00e9\U0001F600", v["a"])
+	assert.Equal(t, `C:\x\u12`, v["b"])
+
+	for _, s := range []string{`\a`, `\v`, `\x41`, `\101`, `\u12`, `\u12"`, `\uD800`, `\U00110000`, `\u+123`} {
+		_, _, err := parseTOML([]byte(`a = "` + s + `"`))
+		assert.Error(t, err, s)
+		if err != nil {
+			assert.Contains(t, err.Error(), "line 1: invalid escape: ")
+		}
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "led")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.json")
+	ioutil.WriteFile(file, []byte(`{ "keys": { "Ctrl-X Ctrl-R": "reload-config" } }`), 0644)
+
+	prompt, term := setup()
+	assert.NoError(t, prompt.LoadConfig(file))
+	ioutil.WriteFile(file, []byte(`{ "prompt": "> " }`), 0644)
+	receive(term, key(CtrlX))
+	receive(term, key(CtrlR))
+	assert.Equal(t, "> ", string(prompt.Prompt))
+
+	ioutil.WriteFile(file, []byte(`{ "keys": { "Ctrl-A": "foo" } }`), 0644)
+	assert.EqualError(t, prompt.ReloadConfig(), file+": line 1: unknown action: foo")
+	reset(term)
+	receive(term, key(CtrlX))
+	receive(term, key(CtrlR))
+	assert.Contains(t, term.out, "unknown action: foo")
+	assert.Contains(t, string(Deansi([]byte(term.out))), "<bell>")
+
+	file = filepath.Join(dir, "config.toml")
+	ioutil.WriteFile(file, []byte("prompt = \"% \"\n"), 0644)
+	assert.NoError(t, prompt.LoadConfig(file))
+	assert.Equal(t, "% ", string(prompt.Prompt))
+}
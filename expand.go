@@ -0,0 +1,309 @@
+package led
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+var (
+	errNoEvent   = errors.New("event not found")
+	errBadWord   = errors.New("bad word specifier")
+	errBadSubst  = errors.New("substitution failed")
+	errBadModify = errors.New("unrecognized history modifier")
+)
+
+// Expand performs csh/bash style history expansion on the given line, using
+// the given history (oldest line first). It supports the event designators
+// `!!`, `!n`, `!-n`, `!prefix`, and `!?substr?`, the word designators `^`,
+// `$`, `*`, `n`, `n-m`, `n-`, `-m`, and `n*` (either after a colon, or
+// directly in case of `^`, `$`, and `*`), the `:s/old/new/` modifier, and the
+// quick substitution `^old^new^` at the beginning of the line.
+//
+// References in single quotes, preceded by a backslash, or a `!` before the
+// closing double quote are left alone.
+func Expand(line []byte, hist [][]byte) ([]byte, error) {
+	if len(line) > 0 && line[0] == '^' {
+		return quickSubst(line, hist)
+	}
+
+	r := []byte{}
+	squoted, dquoted := false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\'' && !dquoted:
+			squoted = !squoted
+		case c == '"' && !squoted:
+			dquoted = !dquoted
+		case c == '\\' && i+1 < len(line):
+			r = append(r, c)
+			i++
+			c = line[i]
+		case c == '!' && !squoted && i+1 < len(line) && !isBangDelim(line[i+1]) && !(dquoted && line[i+1] == '"'):
+			b, n, err := expandRef(line[i+1:], hist)
+			if err != nil {
+				return nil, err
+			}
+			r = append(r, b...)
+			i += n
+			continue
+		}
+		r = append(r, c)
+	}
+	return r, nil
+}
+
+// ExpandHistory performs history expansion (see Expand) on the current line,
+// using the editor's history. If verify is true, and the line has been
+// changed, the expanded line is put back into the editor so the user can
+// review it before submitting it again, and false is returned. Otherwise the
+// current line is replaced with the expanded line, and true is returned,
+// meaning the line can be executed.
+//
+// If HistoryExpand is set, the line is expanded before it is accepted, verified
+// if HistoryVerify is set. Errors are shown below the line, which is kept for
+// the user to fix it.
+func (e *Ed) ExpandHistory(verify bool) (bool, error) {
+	b, err := Expand(e.Chars, e.Hist.Lines())
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(b, e.Chars) {
+		return true, nil
+	}
+	e.Set(b)
+	return !verify, nil
+}
+
+// expandOnAccept expands the line if HistoryExpand is set, and returns
+// whether it is to be accepted.
+func (e *Ed) expandOnAccept() bool {
+	if !e.HistoryExpand {
+		return true
+	}
+	ok, err := e.ExpandHistory(e.HistoryVerify)
+	e.report(err)
+	return ok
+}
+
+// expandRef expands the reference following a `!`, and returns the expanded
+// chars, and the number of chars consumed.
+func expandRef(b []byte, hist [][]byte) ([]byte, int, error) {
+	ev, i, err := event(b, hist)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ev == nil {
+		return nil, 0, errNoEvent
+	}
+
+	if i < len(b) && bytes.IndexByte([]byte("^$*"), b[i]) >= 0 {
+		w, err := words(ev, b[i:i+1])
+		return w, i + 1, err
+	}
+	for i < len(b) && b[i] == ':' {
+		n := wordSpecLen(b[i+1:])
+		if n > 0 {
+			ev, err = words(ev, b[i+1:i+1+n])
+		} else {
+			ev, n, err = modify(ev, b[i+1:])
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		i += 1 + n
+	}
+	return ev, i, nil
+}
+
+// event finds the history line referenced by the given event designator.
+func event(b []byte, hist [][]byte) ([]byte, int, error) {
+	switch {
+	case b[0] == '!':
+		return nth(hist, len(hist)-1), 1, nil
+	case b[0] == ':' || b[0] == '^' || b[0] == '$' || b[0] == '*':
+		return nth(hist, len(hist)-1), 0, nil
+	case b[0] == '-' || isDigit(b[0]):
+		i := 1
+		for i < len(b) && isDigit(b[i]) {
+			i++
+		}
+		n, err := strconv.Atoi(string(b[:i]))
+		if err != nil {
+			return nil, 0, errNoEvent
+		}
+		if n < 0 {
+			n = len(hist) + n
+		} else {
+			n = n - 1
+		}
+		return nth(hist, n), i, nil
+	case b[0] == '?':
+		i := bytes.IndexByte(b[1:], '?')
+		n := i + 2
+		if i < 0 {
+			i, n = len(b)-1, len(b)
+		}
+		if i == 0 {
+			return nil, 0, errNoEvent
+		}
+		return search(hist, b[1:i+1], bytes.Contains), n, nil
+	default:
+		i := 0
+		for i < len(b) && !isBangDelim(b[i]) && b[i] != ':' {
+			i++
+		}
+		return search(hist, b[:i], bytes.HasPrefix), i, nil
+	}
+}
+
+// words selects the words specified by the given word designator from the
+// given line.
+func words(line []byte, spec []byte) ([]byte, error) {
+	w := bytes.Fields(line)
+	last := len(w) - 1
+
+	var from, to int
+	var err error
+	s := string(spec)
+	switch {
+	case s == "^":
+		from, to = 1, 1
+	case s == "$":
+		from, to = last, last
+	case s == "*":
+		if last < 1 {
+			return blank, nil
+		}
+		from, to = 1, last
+	default:
+		from, to, err = wordRange(s, last)
+	}
+
+	if err != nil || from < 0 || to > last || from > to {
+		return nil, errBadWord
+	}
+	return bytes.Join(w[from:to+1], space), nil
+}
+
+func wordRange(s string, last int) (int, int, error) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	from, rest := 0, s[i:]
+	if i > 0 {
+		from, _ = strconv.Atoi(s[:i])
+	}
+
+	switch {
+	case rest == "":
+		return from, from, nil
+	case rest == "*":
+		return from, last, nil
+	case rest == "-":
+		return from, last - 1, nil
+	case rest == "-$":
+		return from, last, nil
+	case rest[0] == '-':
+		to, err := strconv.Atoi(rest[1:])
+		return from, to, err
+	}
+	return 0, 0, errBadWord
+}
+
+// wordSpecLen returns the length of the word designator at the beginning of
+// the given chars, or 0 if there is none.
+func wordSpecLen(b []byte) int {
+	i := 0
+	if i < len(b) && bytes.IndexByte([]byte("^$*"), b[i]) >= 0 {
+		return 1
+	}
+	for i < len(b) && isDigit(b[i]) {
+		i++
+	}
+	if i < len(b) && b[i] == '*' {
+		return i + 1
+	}
+	if i < len(b) && b[i] == '-' {
+		i++
+		if i < len(b) && b[i] == '$' {
+			return i + 1
+		}
+		for i < len(b) && isDigit(b[i]) {
+			i++
+		}
+	}
+	return i
+}
+
+// modify applies the `s/old/new/` modifier at the beginning of the given chars
+// to the given line.
+func modify(line []byte, b []byte) ([]byte, int, error) {
+	if len(b) < 2 || b[0] != 's' {
+		return nil, 0, errBadModify
+	}
+	parts := bytes.SplitN(b[2:], b[1:2], 3)
+	if len(parts) < 2 {
+		return nil, 0, errBadSubst
+	}
+	n := 2 + len(parts[0]) + 1 + len(parts[1])
+	if len(parts) == 3 {
+		n++
+	} else {
+		// the closing delimiter can be omitted at the end of the line
+		n = len(b)
+	}
+	r, err := subst(line, parts[0], parts[1])
+	return r, n, err
+}
+
+func quickSubst(line []byte, hist [][]byte) ([]byte, error) {
+	parts := bytes.SplitN(line[1:], []byte{'^'}, 3)
+	if len(parts) < 2 {
+		return nil, errBadSubst
+	}
+	r, err := subst(nth(hist, len(hist)-1), parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 3 {
+		r = concat(r, parts[2])
+	}
+	return r, nil
+}
+
+func subst(line []byte, old []byte, repl []byte) ([]byte, error) {
+	if line == nil {
+		return nil, errNoEvent
+	}
+	if len(old) == 0 || !bytes.Contains(line, old) {
+		return nil, errBadSubst
+	}
+	return bytes.Replace(line, old, repl, 1), nil
+}
+
+func search(hist [][]byte, str []byte, match func([]byte, []byte) bool) []byte {
+	for i := len(hist) - 1; i >= 0; i-- {
+		if match(hist[i], str) {
+			return hist[i]
+		}
+	}
+	return nil
+}
+
+func nth(hist [][]byte, i int) []byte {
+	if i < 0 || i >= len(hist) {
+		return nil
+	}
+	return hist[i]
+}
+
+func isBangDelim(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '=' || c == '('
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,100 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var expandHist = [][]byte{
+	[]byte("git status"),
+	[]byte("ls -la /tmp"),
+	[]byte("echo foo bar baz"),
+}
+
+func TestExpand(t *testing.T) {
+	tests := map[string]string{
+		"sudo !!":        "sudo echo foo bar baz",
+		"!1":             "git status",
+		"!-2":            "ls -la /tmp",
+		"!git":           "git status",
+		"!?tmp?":         "ls -la /tmp",
+		"!?tmp":          "ls -la /tmp",
+		"cat !$":         "cat baz",
+		"cat !^":         "cat foo",
+		"cat !*":         "cat foo bar baz",
+		"cat !!:1-2":     "cat foo bar",
+		"cat !!:2*":      "cat bar baz",
+		"cat !!:1-":      "cat foo bar",
+		"cat !ls:2":      "cat /tmp",
+		"!!:s/foo/qux/":  "echo qux bar baz",
+		"^foo^qux^ -n":   "echo qux bar baz -n",
+		"^bar^qux":       "echo foo qux baz",
+		"echo '!!'":      "echo '!!'",
+		"echo \\!!":      "echo \\!!",
+		"echo ! x":       "echo ! x",
+		"a != b":         "a != b",
+		"echo !$ and !^": "echo baz and foo",
+		`echo "hi!"`:     `echo "hi!"`,
+		`echo "it's !$"`: `echo "it's baz"`,
+		`echo '"' !$`:    `echo '"' baz`,
+	}
+	for line, expected := range tests {
+		b, err := Expand([]byte(line), expandHist)
+		assert.NoError(t, err, line)
+		assert.Equal(t, expected, string(b), line)
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	for _, line := range []string{"!nope", "!9", "!!:7", "^nope^foo", "!!:x", "!?", "!??"} {
+		_, err := Expand([]byte(line), expandHist)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestExpandHistoryVerify(t *testing.T) {
+	prompt, term := setup()
+	for _, l := range expandHist {
+		prompt.Hist.Add(l)
+	}
+	receive(term, "sudo !!")
+	ok, err := prompt.ExpandHistory(true)
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "sudo echo foo bar baz", prompt.Str())
+
+	ok, err = prompt.ExpandHistory(true)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHistoryExpandOnAccept(t *testing.T) {
+	prompt, term := setup()
+	for _, l := range expandHist {
+		prompt.Hist.Add(l)
+	}
+	accepted := []string{}
+	prompt.Handle(Enter, func(e *Ed, k Key) { accepted = append(accepted, e.Str()) })
+	prompt.HistoryExpand = true
+	receive(term, "sudo !!")
+	receive(term, key(Enter))
+	assert.Equal(t, []string{"sudo echo foo bar baz"}, accepted)
+
+	prompt.Reset()
+	prompt.HistoryVerify = true
+	receive(term, "cat !$")
+	receive(term, key(Enter))
+	assert.Equal(t, "cat baz", prompt.Str())
+	assert.Len(t, accepted, 1)
+	receive(term, key(Enter))
+	assert.Equal(t, []string{"sudo echo foo bar baz", "cat baz"}, accepted)
+
+	prompt.Reset()
+	reset(term)
+	receive(term, "!nope")
+	receive(term, key(Enter))
+	assert.Len(t, accepted, 2)
+	assert.Equal(t, "!nope", prompt.Str())
+	assert.Contains(t, term.out, "event not found")
+}
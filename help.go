@@ -0,0 +1,143 @@
+package led
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Binding represents a key, or a sequence of keys, and the name of the action
+// it runs. The action is empty for handlers that were not attached by name.
+type Binding struct {
+	Keys   string
+	Action string
+}
+
+// Bindings returns the bindings of the current keymap, sorted by action and
+// keys.
+func (e *Ed) Bindings() []Binding {
+	b := []Binding{}
+	for code, h := range e.handlers {
+		if h != nil {
+			b = append(b, Binding{codeName(code), e.names[code]})
+		}
+	}
+	for seq, h := range e.seqs {
+		if h != nil {
+			b = append(b, Binding{keysName([]byte(seq)), e.seqNames[seq]})
+		}
+	}
+	sort.Slice(b, func(i, j int) bool {
+		if b[i].Action != b[j].Action {
+			return b[i].Action < b[j].Action
+		}
+		return b[i].Keys < b[j].Keys
+	})
+	return b
+}
+
+// ListBindings shows the bindings of the current keymap below the line, one
+// action per line, like `bind -P` in Bash.
+func (e *Ed) ListBindings() {
+	keys := map[string][]string{}
+	names := []string{}
+	width := 0
+	for _, b := range e.Bindings() {
+		name := b.Action
+		if name == "" {
+			name = "(custom)"
+		}
+		if _, ok := keys[name]; !ok {
+			names = append(names, name)
+			width = max(width, len(name))
+		}
+		keys[name] = append(keys[name], b.Keys)
+	}
+
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%-*s  %s", width, name, strings.Join(keys[name], ", ")))
+	}
+	e.Overlay(lines...)
+}
+
+// DescribeKey waits for the next key, or sequence of keys, and shows the
+// action it runs below the line, instead of running it.
+func (e *Ed) DescribeKey() {
+	e.describing = true
+}
+
+// Overlay shows the given lines below the current line, and redraws the line
+// below them.
+func (e *Ed) Overlay(lines ...string) {
+	for _, l := range lines {
+		e.Write([]byte("\r\n" + l))
+	}
+	e.Write([]byte("\r\n"))
+	e.Refresh()
+}
+
+// report rings the bell, and shows the given error below the line, if any.
+func (e *Ed) report(err error) {
+	if err != nil {
+		e.Bell()
+		e.Overlay(strings.Split(err.Error(), "\n")...)
+	}
+}
+
+// describe shows the action bound to the given keys.
+func (e *Ed) describe(keys []Key, bound bool) {
+	seq, name := e.actionName(keys)
+
+	switch {
+	case !bound:
+		e.Overlay(keysName(seq) + " is not bound")
+	case name == "":
+		e.Overlay(keysName(seq) + " runs a custom handler")
+	default:
+		e.Overlay(keysName(seq) + " runs " + name)
+	}
+}
+
+// actionName returns the chars of the given keys, and the name of the action
+// bound to them, or an empty name for custom handlers.
+func (e *Ed) actionName(keys []Key) ([]byte, string) {
+	seq := []byte{}
+	for _, k := range keys {
+		seq = append(seq, k.Chars...)
+	}
+	if _, ok := e.seqs[string(seq)]; ok {
+		return seq, e.seqNames[string(seq)]
+	}
+	return seq, e.names[keys[len(keys)-1].Code]
+}
+
+// codeName returns the name of the key with the given code.
+func codeName(code int) string {
+	if code == Chars {
+		return "Chars"
+	}
+	return Keys[code].Name
+}
+
+// keysName returns the names of the keys in the given sequence, separated by
+// spaces, e.g. `Ctrl-X Ctrl-U`.
+func keysName(seq []byte) string {
+	names := []string{}
+	for _, k := range ParseKeys(seq) {
+		c := k.Chars
+		switch {
+		case k.Code != Chars:
+			names = append(names, k.Name)
+		case len(c) == 2 && c[0] == 0x1b:
+			names = append(names, "Alt-"+strings.ToUpper(string(c[1:])))
+		case len(c) == 1 && c[0] < ' ':
+			names = append(names, "Ctrl-"+string(c[0]+'@'))
+		case len(c) == 1 && c[0] == ' ':
+			names = append(names, "Space")
+		default:
+			names = append(names, string(c))
+		}
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,71 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBindings(t *testing.T) {
+	prompt, _ := setup()
+	prompt.Handle(CtrlT, func(e *Ed, k Key) {})
+	b := prompt.Bindings()
+	assert.Equal(t, Binding{"Ctrl-T", ""}, b[0])
+	assert.Contains(t, b, Binding{"Ctrl-A", "beginning-of-line"})
+	assert.Contains(t, b, Binding{"Ctrl-X Ctrl-U", "undo"})
+	assert.Contains(t, b, Binding{"Ctrl-X (", "start-kbd-macro"})
+	assert.Contains(t, b, Binding{"Chars", "self-insert"})
+}
+
+func TestListBindings(t *testing.T) {
+	prompt := NewEd("t ~ ", newTestTerm())
+	prompt.HandleAction(CtrlA, "beginning-of-line")
+	prompt.HandleAction(CtrlE, "end-of-line")
+	prompt.HandleAction(Right, "end-of-line")
+	prompt.Chars = []byte("foo")
+	prompt.Pos = 1
+	prompt.ListBindings()
+	assertOut(t, prompt.term.tty.(*testTerm), []string{
+		"<cr><nl>beginning-of-line  Ctrl-A",
+		"<cr><nl>end-of-line        Ctrl-E, Right",
+		"<cr><nl>",
+		"<cr><clear>t ~ foo<cr><rgt-5>",
+	})
+}
+
+func TestDescribeKey(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo")
+	receive(term, key(CtrlX))
+	receive(term, "k")
+	reset(term)
+	receive(term, key(CtrlA))
+	assert.Equal(t, 3, prompt.Pos)
+	assertOut(t, term, []string{
+		"<cr><nl>Ctrl-A runs beginning-of-line",
+		"<cr><nl>",
+		"<cr><clear>t ~ foo<cr><rgt-7>",
+	})
+
+	prompt.DescribeKey()
+	receive(term, key(CtrlX))
+	receive(term, key(CtrlU))
+	assert.Equal(t, "foo", prompt.Str())
+
+	reset(term)
+	prompt.DescribeKey()
+	receive(term, key(Esc))
+	assertOut(t, term, []string{
+		"<cr><nl>Esc is not bound",
+		"<cr><nl>",
+		"<cr><clear>t ~ foo<cr><rgt-7>",
+	})
+	receive(term, "x")
+	assert.Equal(t, "foox", prompt.Str())
+}
+
+func TestKeysName(t *testing.T) {
+	assert.Equal(t, "Ctrl-X Ctrl-U", keysName(Seq(CtrlX, CtrlU)))
+	assert.Equal(t, "Ctrl-X e", keysName(append(Seq(CtrlX), 'e')))
+	assert.Equal(t, "Ctrl-G Space", keysName([]byte{0x07, ' '}))
+	assert.Equal(t, "Alt-F", keysName(Seq(AltF)))
+}
@@ -18,6 +18,16 @@ func min(i ...int) int {
 	return min
 }
 
+func max(i ...int) int {
+	max := i[0]
+	for _, num := range i[0:] {
+		if num > max {
+			max = num
+		}
+	}
+	return max
+}
+
 func trimSpace(b []byte) []byte {
 	for bytes.HasSuffix(b, space) {
 		bytes.TrimSuffix(b, space)
@@ -25,38 +35,6 @@ func trimSpace(b []byte) []byte {
 	return b
 }
 
-func trimLastWord(b []byte) []byte {
-	a := bytes.Split(b, space)
-	return bytes.Join(a[:len(a)-1], space)
-}
-
-func firstWord(b []byte) []byte {
-	w := bytes.Split(b, space)
-	if len(w) > 0 {
-		return w[0]
-	}
-	return []byte{}
-}
-
-func lastWord(b []byte, skipSpace ...bool) []byte {
-	w := bytes.Split(b, space)
-	if len(w) == 0 {
-		return []byte{}
-	} else if len(skipSpace) > 0 && skipSpace[0] {
-		b = []byte{}
-		for i := len(w) - 1; i >= 0; i-- {
-			if len(w[i]) == 0 {
-				b = append(b, space...)
-			} else {
-				return append(w[i], b...)
-			}
-		}
-		return b
-	} else {
-		return w[len(w)-1]
-	}
-}
-
 func hasTailingSpace(b []byte) bool {
 	return len(b) > 0 && b[len(b)-1] == ' '
 }
@@ -92,3 +70,12 @@ func indexOf(strs [][]byte, str []byte) int {
 	}
 	return -1
 }
+
+func indexOfStr(strs []string, s string) int {
+	for i, str := range strs {
+		if str == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,357 @@
+package led
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// nav keeps track of the position while navigating the history. Like
+// Readline it keeps the line that was being typed when navigation started (the
+// draft) as a virtual last line, and remembers edits made to recalled lines
+// until the line is submitted.
+//
+// In Hist mode lines are matched by the first word of the draft. In Prefix
+// and Substr mode the draft up to the cursor position is used as a prefix or
+// substring, and the cursor is kept after the matching part of a line.
+type nav struct {
+	list  *List
+	lines [][]byte
+	curr  int
+	edits [][]byte
+	all   bool
+}
+
+func newNav(strs [][]byte, draft []byte, pos int, mode int, m Matcher) *nav {
+	str := draft
+	if mode == Prefix || mode == Substr {
+		str = draft[:pos]
+	}
+	l := NewList(strs, str, mode)
+	l.Matcher = m
+	lines := append([][]byte{}, l.matches()...)
+	if m != nil {
+		// moving back from the draft, show the best matches first
+		score := func(b []byte) int { s, _ := m.Match(b, l.str); return s }
+		sort.SliceStable(lines, func(i, j int) bool { return score(lines[i]) < score(lines[j]) })
+	}
+	lines = append(lines, dup(draft))
+	return &nav{list: l, lines: lines, curr: len(lines) - 1, edits: make([][]byte, len(lines))}
+}
+
+// allNav returns a nav through all of the given lines, ignoring the draft
+// for matching.
+func allNav(strs [][]byte, draft []byte, mode int) *nav {
+	n := newNav(strs, []byte{}, 0, mode, nil)
+	n.lines[len(n.lines)-1] = dup(draft)
+	n.all = true
+	return n
+}
+
+func (n *nav) eq(strs [][]byte, mode int) bool {
+	return n.list.mode == mode && n.list.eq(&List{strs: strs})
+}
+
+// save remembers the given chars as the current line, if they differ from the
+// original line.
+func (n *nav) save(b []byte) {
+	if bytes.Equal(b, n.lines[n.curr]) {
+		n.edits[n.curr] = nil
+	} else {
+		n.edits[n.curr] = dup(b)
+	}
+}
+
+// move moves to the previous or next line depending on the given direction,
+// and returns it, or nil if there is no such line.
+func (n *nav) move(dir int) []byte {
+	i := n.curr + step(dir)
+	if i < 0 || i >= len(n.lines) {
+		return nil
+	}
+	n.curr = i
+	return n.line()
+}
+
+// jump moves to the line at the given index, and returns it.
+func (n *nav) jump(i int) []byte {
+	n.curr = i
+	return n.line()
+}
+
+// draft returns the line being typed, including any edits.
+func (n *nav) draft() []byte {
+	i := len(n.lines) - 1
+	if n.edits[i] != nil {
+		return dup(n.edits[i])
+	}
+	return dup(n.lines[i])
+}
+
+// line returns the current line, including any edits.
+func (n *nav) line() []byte {
+	if n.edits[n.curr] != nil {
+		return dup(n.edits[n.curr])
+	}
+	return dup(n.lines[n.curr])
+}
+
+// cursor returns the cursor position for the given line.
+func (n *nav) cursor(b []byte) int {
+	if n.all {
+		return len(b)
+	}
+	switch n.list.mode {
+	case Prefix:
+		if bytes.HasPrefix(b, n.list.str) {
+			return len(n.list.str)
+		}
+	case Substr:
+		if i := bytes.Index(b, n.list.str); i >= 0 {
+			return i + len(n.list.str)
+		}
+	}
+	return len(b)
+}
+
+// NewHistory creates a history, and loads it from the given file, if any.
+// Errors reading the file are ignored, see Load.
+func NewHistory(file ...string) *History {
+	h := &History{lines: [][]byte{}}
+	if len(file) > 0 {
+		h.File = file[0]
+		h.Load()
+	}
+	return h
+}
+
+// History represents the lines entered by the user, optionally persisted to
+// a file. Lines containing newlines are stored with a trailing backslash on
+// all but the last line.
+type History struct {
+	// File is the path to the history file, if any.
+	File string
+	// IgnoreSpace skips lines starting with a space.
+	IgnoreSpace bool
+	// IgnoreDups skips lines matching the previous line.
+	IgnoreDups bool
+	// EraseDups removes all previous occurrences of a line before adding it,
+	// rewriting the history file.
+	EraseDups bool
+	// Ignore skips lines matching any of the given patterns, like Bash's
+	// HISTIGNORE. A pattern must match the whole line, `*` matches any
+	// chars, `?` matches a single char, `[...]` matches one of the enclosed
+	// chars, and `&` matches the previous line. Add returns an error if a
+	// pattern is invalid, use SetIgnore to validate them upfront.
+	Ignore []string
+	lines  [][]byte
+}
+
+// Lines returns the lines in the history, oldest line first.
+func (h *History) Lines() [][]byte {
+	return h.lines
+}
+
+// Add adds the given line to the history, unless it is empty or skipped
+// according to the history's settings, and appends it to the history file.
+func (h *History) Add(b []byte) error {
+	if skip, err := h.skip(b); skip || err != nil {
+		return err
+	}
+	b = dup(b)
+
+	erased := false
+	if h.EraseDups {
+		lines := [][]byte{}
+		for _, l := range h.lines {
+			if !bytes.Equal(l, b) {
+				lines = append(lines, l)
+			}
+		}
+		erased = len(lines) < len(h.lines)
+		h.lines = lines
+	}
+	h.lines = append(h.lines, b)
+
+	if h.File == "" {
+		return nil
+	} else if erased {
+		return h.Save()
+	}
+	f, err := os.OpenFile(h.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(encodeLine(b))
+	return err
+}
+
+// Load reads the history file. A missing file is not an error.
+func (h *History) Load() error {
+	data, err := ioutil.ReadFile(h.File)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	h.lines = decodeLines(data)
+	return nil
+}
+
+// Save writes all lines to the history file.
+func (h *History) Save() error {
+	data := []byte{}
+	for _, l := range h.lines {
+		data = append(data, encodeLine(l)...)
+	}
+	return ioutil.WriteFile(h.File, data, 0600)
+}
+
+func (h *History) skip(b []byte) (bool, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return true, nil
+	}
+	if h.IgnoreSpace && b[0] == ' ' {
+		return true, nil
+	}
+	prev := nth(h.lines, len(h.lines)-1)
+	if h.IgnoreDups && bytes.Equal(b, prev) {
+		return true, nil
+	}
+	for _, p := range h.Ignore {
+		if p == "&" {
+			if bytes.Equal(b, prev) {
+				return true, nil
+			}
+			continue
+		}
+		r, err := glob(p)
+		if err != nil {
+			return false, fmt.Errorf("invalid ignore pattern: %s", p)
+		}
+		if r.Match(b) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// glob translates the given shell pattern into a regexp matching whole
+// lines. Bracket expressions support ranges, negation with `!` or `^`, and
+// character classes like `[:alpha:]`. An unclosed `[` matches itself.
+func glob(p string) (*regexp.Regexp, error) {
+	r := []byte{'^'}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			r = append(r, ".*"...)
+		case '?':
+			r = append(r, '.')
+		case '[':
+			if class, n := globClass(p[i:]); n > 0 {
+				r = append(r, class...)
+				i += n - 1
+			} else {
+				r = append(r, regexp.QuoteMeta(string(c))...)
+			}
+		case '\\':
+			if i+1 < len(p) {
+				i++
+				r = append(r, regexp.QuoteMeta(p[i:i+1])...)
+			}
+		default:
+			r = append(r, regexp.QuoteMeta(string(c))...)
+		}
+	}
+	return regexp.Compile(string(append(r, '$')))
+}
+
+// globClass translates the bracket expression at the start of the given
+// pattern into a regexp class, and returns it with the length of the
+// expression, or 0 if it is not closed. A `]` right after the opening
+// bracket (and negation) is matched literally.
+func globClass(p string) (string, int) {
+	r := []byte{'['}
+	i := 1
+	if i < len(p) && (p[i] == '!' || p[i] == '^') {
+		r = append(r, '^')
+		i++
+	}
+	for start := i; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == ']' && i > start:
+			return string(append(r, ']')), i + 1
+		case c == '[' && i+1 < len(p) && p[i+1] == ':':
+			j := strings.Index(p[i+2:], ":]")
+			if j == -1 {
+				return "", 0
+			}
+			r = append(r, p[i:i+j+4]...)
+			i += j + 3
+		case c == '\\' && i+1 < len(p):
+			i++
+			r = append(r, '\\', p[i])
+		case c == '-':
+			r = append(r, c)
+		case strings.IndexByte(`\[]^`, c) != -1:
+			r = append(r, '\\', c)
+		default:
+			r = append(r, c)
+		}
+	}
+	return "", 0
+}
+
+// SetIgnore sets the patterns for skipping lines, see Ignore, and returns an
+// error if any of them is invalid.
+func (h *History) SetIgnore(patterns ...string) error {
+	for _, p := range patterns {
+		if _, err := glob(p); p != "&" && err != nil {
+			return fmt.Errorf("invalid ignore pattern: %s", p)
+		}
+	}
+	h.Ignore = patterns
+	return nil
+}
+
+// encodeLine escapes backslashes, and newlines with a backslash, so lines
+// ending in a backslash aren't mistaken for continued lines.
+func encodeLine(b []byte) []byte {
+	b = bytes.Replace(b, []byte(`\`), []byte(`\\`), -1)
+	return append(bytes.Replace(b, newline, []byte("\\\n"), -1), '\n')
+}
+
+// decodeLines splits the given data into lines, joining lines ending in an
+// unescaped backslash with the next one. Escaped backslashes are unescaped,
+// other backslashes are kept as is, as written by earlier versions.
+func decodeLines(data []byte) [][]byte {
+	strs := [][]byte{}
+	curr := []byte{}
+	for _, l := range splitLines(data) {
+		continued := false
+		for i := 0; i < len(l); i++ {
+			switch {
+			case l[i] == '\\' && i == len(l)-1:
+				continued = true
+			case l[i] == '\\' && l[i+1] == '\\':
+				curr = append(curr, '\\')
+				i++
+			default:
+				curr = append(curr, l[i])
+			}
+		}
+		if continued {
+			curr = append(curr, '\n')
+			continue
+		}
+		strs = appendLine(strs, curr)
+		curr = []byte{}
+	}
+	return strs
+}
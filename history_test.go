@@ -0,0 +1,107 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	h := NewHistory()
+	h.Add([]byte("foo"))
+	h.Add([]byte(""))
+	h.Add([]byte("foo"))
+	assert.Equal(t, []string{"foo", "foo"}, strs(h.Lines()))
+}
+
+func TestHistoryIgnoreSpace(t *testing.T) {
+	h := NewHistory()
+	h.IgnoreSpace = true
+	h.Add([]byte(" export TOKEN=secret"))
+	h.Add([]byte("foo"))
+	assert.Equal(t, []string{"foo"}, strs(h.Lines()))
+}
+
+func TestHistoryIgnoreDups(t *testing.T) {
+	h := NewHistory()
+	h.IgnoreDups = true
+	for _, l := range []string{"foo", "foo", "bar", "foo"} {
+		h.Add([]byte(l))
+	}
+	assert.Equal(t, []string{"foo", "bar", "foo"}, strs(h.Lines()))
+}
+
+func TestHistoryIgnore(t *testing.T) {
+	h := NewHistory()
+	h.Ignore = []string{"ls", "export *", "[bf]g", "&"}
+	for _, l := range []string{"ls", "ls -la", "export TOKEN=x", "fg", "cd /", "cd /"} {
+		h.Add([]byte(l))
+	}
+	assert.Equal(t, []string{"ls -la", "cd /"}, strs(h.Lines()))
+}
+
+func TestHistoryIgnoreBrackets(t *testing.T) {
+	h := NewHistory()
+	assert.NoError(t, h.SetIgnore("[]", "[[:digit:]]x", "[!a-c]?", "[]a]"))
+	for _, l := range []string{"[]", "1x", "ax", "dx", "]", "b"} {
+		h.Add([]byte(l))
+	}
+	assert.Equal(t, []string{"ax", "b"}, strs(h.Lines()))
+
+	assert.EqualError(t, h.SetIgnore("ls", "[z-a]"), "invalid ignore pattern: [z-a]")
+	h.Ignore = []string{"[z-a]"}
+	assert.EqualError(t, h.Add([]byte("z")), "invalid ignore pattern: [z-a]")
+	assert.Equal(t, []string{"ax", "b"}, strs(h.Lines()))
+}
+
+func TestHistoryFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "led")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "history")
+
+	h := NewHistory(file)
+	h.Add([]byte("foo"))
+	h.Add([]byte("bar\nbaz"))
+	data, _ := ioutil.ReadFile(file)
+	assert.Equal(t, "foo\nbar\\\nbaz\n", string(data))
+	assert.Equal(t, []string{"foo", "bar\nbaz"}, strs(NewHistory(file).Lines()))
+}
+
+func TestHistoryFileBackslashes(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "led")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "history")
+
+	lines := []string{`echo foo \`, "ls", `a\\b\`, "x \\\ny\\", `grep a\|b`}
+	h := NewHistory(file)
+	for _, l := range lines {
+		h.Add([]byte(l))
+	}
+	assert.Equal(t, lines, strs(NewHistory(file).Lines()))
+	assert.NoError(t, h.Save())
+	assert.Equal(t, lines, strs(NewHistory(file).Lines()))
+
+	ioutil.WriteFile(file, []byte("grep a\\|b\ncat \\\nfoo\n"), 0600)
+	assert.Equal(t, []string{`grep a\|b`, "cat \nfoo"}, strs(NewHistory(file).Lines()))
+}
+
+func TestHistoryEraseDups(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "led")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "history")
+
+	h := NewHistory(file)
+	h.EraseDups = true
+	for _, l := range []string{"foo", "bar", "foo"} {
+		h.Add([]byte(l))
+	}
+	data, _ := ioutil.ReadFile(file)
+	assert.Equal(t, "bar\nfoo\n", string(data))
+}
+
+func TestUniq(t *testing.T) {
+	assert.Equal(t, []string{"b", "c", "a"}, strs(uniq(bytesOf("a", "b", "a", "c", "c", "a"))))
+	assert.Equal(t, []string{}, strs(uniq(bytesOf())))
+}
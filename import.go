@@ -0,0 +1,165 @@
+package led
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	regBashTime = regexp.MustCompile(`^#[0-9]+$`)
+	regZshTime  = regexp.MustCompile(`^: *[0-9]+:[0-9]+;`)
+	newline     = []byte{'\n'}
+)
+
+// zshMeta is the byte zsh uses to mark metafied chars in its history file.
+const zshMeta = 0x83
+
+// ImportBash reads the lines from a bash history file, defaulting to
+// ~/.bash_history. Timestamp lines (`#1528364560`) are skipped, and if the
+// file contains them they are used to group multi-line commands.
+func ImportBash(path ...string) ([][]byte, error) {
+	b, err := readHistory(path, ".bash_history")
+	if err != nil {
+		return nil, err
+	}
+	return parseBash(b), nil
+}
+
+// ImportZsh reads the lines from a zsh history file, defaulting to
+// ~/.zsh_history. Both the plain and the extended format
+// (`: 1528364560:0;cmd`) are supported, metafied bytes are restored, and
+// multi-line commands are joined.
+func ImportZsh(path ...string) ([][]byte, error) {
+	b, err := readHistory(path, ".zsh_history")
+	if err != nil {
+		return nil, err
+	}
+	return parseZsh(b), nil
+}
+
+// ImportFish reads the commands from a fish history file, defaulting to
+// $XDG_DATA_HOME/fish/fish_history.
+func ImportFish(path ...string) ([][]byte, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		dir = filepath.Join(".local", "share")
+	}
+	b, err := readHistory(path, filepath.Join(dir, "fish", "fish_history"))
+	if err != nil {
+		return nil, err
+	}
+	return parseFish(b), nil
+}
+
+func readHistory(path []string, name string) ([]byte, error) {
+	if len(path) > 0 {
+		return ioutil.ReadFile(path[0])
+	}
+	if !filepath.IsAbs(name) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		name = filepath.Join(home, name)
+	}
+	return ioutil.ReadFile(name)
+}
+
+func parseBash(b []byte) [][]byte {
+	lines := splitLines(b)
+	stamped := false
+	for _, l := range lines {
+		if regBashTime.Match(l) {
+			stamped = true
+			break
+		}
+	}
+
+	strs := [][]byte{}
+	var curr [][]byte
+	for _, l := range lines {
+		if regBashTime.Match(l) {
+			strs = appendLine(strs, bytes.Join(curr, newline))
+			curr = nil
+		} else if stamped && len(l) > 0 {
+			curr = append(curr, l)
+		} else if !stamped {
+			strs = appendLine(strs, l)
+		}
+	}
+	return appendLine(strs, bytes.Join(curr, newline))
+}
+
+func parseZsh(b []byte) [][]byte {
+	strs := [][]byte{}
+	var curr []byte
+	for _, l := range splitLines(unmetafy(b)) {
+		if curr == nil {
+			l = regZshTime.ReplaceAll(l, blank)
+		}
+		if bytes.HasSuffix(l, []byte{'\\'}) {
+			curr = concat(curr, l[:len(l)-1], newline)
+			continue
+		}
+		strs = appendLine(strs, concat(curr, l))
+		curr = nil
+	}
+	return appendLine(strs, curr)
+}
+
+func parseFish(b []byte) [][]byte {
+	strs := [][]byte{}
+	prefix := []byte("- cmd: ")
+	for _, l := range splitLines(b) {
+		if bytes.HasPrefix(l, prefix) {
+			strs = appendLine(strs, unescapeFish(l[len(prefix):]))
+		}
+	}
+	return strs
+}
+
+func unmetafy(b []byte) []byte {
+	r := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == zshMeta && i+1 < len(b) {
+			i++
+			r = append(r, b[i]^0x20)
+		} else {
+			r = append(r, b[i])
+		}
+	}
+	return r
+}
+
+func unescapeFish(b []byte) []byte {
+	r := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == '\\' && i+1 < len(b) {
+			i++
+			if b[i] == 'n' {
+				r = append(r, '\n')
+				continue
+			}
+		}
+		r = append(r, b[i])
+	}
+	return r
+}
+
+func splitLines(b []byte) [][]byte {
+	lines := bytes.Split(b, newline)
+	for i, l := range lines {
+		lines[i] = bytes.TrimSuffix(l, []byte{'\r'})
+	}
+	return lines
+}
+
+func appendLine(strs [][]byte, b []byte) [][]byte {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return strs
+	}
+	return append(strs, b)
+}
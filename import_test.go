@@ -0,0 +1,45 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseBash(t *testing.T) {
+	h := parseBash([]byte("ls -la\n\ncd /tmp\n"))
+	assert.Equal(t, []string{"ls -la", "cd /tmp"}, strs(h))
+}
+
+func TestParseBashTimestamps(t *testing.T) {
+	h := parseBash([]byte("#1528364560\nls -la\n#1528364561\nfor i in 1 2; do\necho $i\ndone\n"))
+	assert.Equal(t, []string{"ls -la", "for i in 1 2; do\necho $i\ndone"}, strs(h))
+}
+
+func TestParseZsh(t *testing.T) {
+	h := parseZsh([]byte("ls -la\ncd /tmp\n"))
+	assert.Equal(t, []string{"ls -la", "cd /tmp"}, strs(h))
+}
+
+func TestParseZshExtended(t *testing.T) {
+	h := parseZsh([]byte(": 1528364560:0;ls -la\n: 1528364561:2;echo foo\\\nbar\n"))
+	assert.Equal(t, []string{"ls -la", "echo foo\nbar"}, strs(h))
+}
+
+func TestParseZshMetafied(t *testing.T) {
+	// "ü" is 0xc3 0xbc, zsh writes 0xbc as 0x83 0x9c
+	h := parseZsh([]byte(": 1528364560:0;echo \xc3\x83\x9c\n"))
+	assert.Equal(t, []string{"echo ü"}, strs(h))
+}
+
+func TestParseFish(t *testing.T) {
+	h := parseFish([]byte("- cmd: ls -la\n  when: 1528364560\n- cmd: echo foo\\nbar \\\\o/\n  when: 1528364561\n  paths:\n    - foo\n"))
+	assert.Equal(t, []string{"ls -la", "echo foo\nbar \\o/"}, strs(h))
+}
+
+func strs(b [][]byte) []string {
+	s := []string{}
+	for _, l := range b {
+		s = append(s, string(l))
+	}
+	return s
+}
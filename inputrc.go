@@ -0,0 +1,447 @@
+package led
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterAction("re-read-init-file", func(e *Ed, k Key) { e.rereadInputrc() })
+}
+
+// keyNames maps the key names that can be used in inputrc bindings to their
+// chars.
+var keyNames = map[string]byte{
+	"del":     0x7f,
+	"esc":     0x1b,
+	"escape":  0x1b,
+	"lfd":     '\n',
+	"newline": '\n',
+	"ret":     '\r',
+	"return":  '\r',
+	"rubout":  0x7f,
+	"space":   ' ',
+	"spc":     ' ',
+	"tab":     '\t',
+}
+
+// LoadInputrc reads key bindings and variables from the given readline init
+// file, defaulting to $INPUTRC, or ~/.inputrc.
+//
+// Bindings can be given as key names (`Control-u: unix-line-discard`,
+// `Meta-f: forward-word`), or as quoted key sequences (`"\C-x\C-r":
+// re-read-init-file`), and are bound to a readline function (see Actions),
+// or to a quoted macro that is typed in when the keys are pressed
+// (`"\C-xg": "git status\r"`).
+//
+// The variables editing-mode (emacs, vi), completion-ignore-case (on, off),
+// completion-query-items (a number), and bell-style (none, audible, visible)
+// are supported, other variables are ignored. The directives `$if`, `$else`,
+// `$endif`, and `$include` are supported as well, where `$if` tests the
+// editing mode (`mode=vi`) or the terminal (`term=xterm`).
+//
+// Setting the editing mode replaces all handlers, so init files should be
+// loaded before attaching custom handlers. Unknown functions and invalid
+// lines are reported as an error after reading the whole file.
+func (e *Ed) LoadInputrc(path ...string) error {
+	name, err := inputrcPath(path)
+	if err != nil {
+		return err
+	}
+	e.inputrc = name
+	return e.includeInputrc(name)
+}
+
+func inputrcPath(path []string) (string, error) {
+	if len(path) > 0 {
+		return path[0], nil
+	}
+	if name := os.Getenv("INPUTRC"); name != "" {
+		return name, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".inputrc"), nil
+}
+
+// rereadInputrc reads the init file again, reporting any errors.
+func (e *Ed) rereadInputrc() {
+	if e.inputrc != "" {
+		e.report(e.includeInputrc(e.inputrc))
+	}
+}
+
+// includeInputrc reads the given init file, unless it is already being read,
+// i.e. it includes itself directly or via other files.
+func (e *Ed) includeInputrc(name string) error {
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+	if indexOfStr(e.including, path) != -1 {
+		return fmt.Errorf("recursive include: %s", name)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	e.including = append(e.including, path)
+	defer func() { e.including = e.including[:len(e.including)-1] }()
+	return e.parseInputrc(f, name)
+}
+
+// cond represents an `$if` directive while parsing an init file.
+type cond struct {
+	outer bool
+	test  bool
+	alt   bool
+}
+
+func (c cond) active() bool {
+	return c.outer && c.test != c.alt
+}
+
+func (e *Ed) parseInputrc(r io.Reader, name string) error {
+	var errs []string
+	conds := []cond{}
+	active := func() bool {
+		return len(conds) == 0 || conds[len(conds)-1].active()
+	}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		var err error
+		directive, arg := splitWord(line)
+		switch {
+		case directive == "$if":
+			conds = append(conds, cond{outer: active(), test: e.test(arg)})
+		case directive == "$else" && len(conds) > 0:
+			conds[len(conds)-1].alt = true
+		case directive == "$endif" && len(conds) > 0:
+			conds = conds[:len(conds)-1]
+		case !active():
+		case directive == "$include":
+			err = e.includeInputrc(expandHome(arg))
+		case directive == "set":
+			err = e.setVar(splitWord(arg))
+		default:
+			err = e.bindLine(line)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s:%d: %v", name, n, err))
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// test evaluates the condition of an `$if` directive.
+func (e *Ed) test(s string) bool {
+	name, value := splitPair(s, "=")
+	switch name {
+	case "mode":
+		return value == e.EditingMode()
+	case "term":
+		term := os.Getenv("TERM")
+		return term == value || strings.HasPrefix(term, value+"-")
+	}
+	return false
+}
+
+func (e *Ed) setVar(name string, value string) error {
+	switch strings.ToLower(name) {
+	case "editing-mode":
+		return e.SetEditingMode(value)
+	case "completion-ignore-case":
+		e.CompletionIgnoreCase = isOn(value)
+	case "completion-query-items":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid completion-query-items: %s", value)
+		}
+		e.CompletionQueryItems = n
+	case "bell-style":
+		switch value {
+		case "none", "audible", "visible":
+			e.BellStyle = value
+		default:
+			return fmt.Errorf("invalid bell-style: %s", value)
+		}
+	}
+	return nil
+}
+
+func (e *Ed) bindLine(line string) error {
+	var seq []byte
+	var err error
+	var rest string
+	if line[0] == '"' {
+		i := closingQuote(line, '"')
+		if i < 0 {
+			return fmt.Errorf("unterminated key sequence: %s", line)
+		}
+		seq, rest = unescapeKeys(line[1:i]), line[i+1:]
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, ":") {
+			return fmt.Errorf("missing colon: %s", line)
+		}
+		rest = rest[1:]
+	} else {
+		i := strings.Index(line, ":")
+		if i < 1 {
+			return fmt.Errorf("missing colon: %s", line)
+		}
+		seq, err = parseKeyName(line[:i])
+		if err != nil {
+			return err
+		}
+		rest = line[i+1:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+		i := closingQuote(rest, rest[0])
+		if i < 0 {
+			return fmt.Errorf("unterminated macro: %s", rest)
+		}
+		keys := ParseKeys(unescapeKeys(rest[1:i]))
+		e.bind(seq, func(e *Ed, k Key) { e.play(keys) })
+		return nil
+	}
+
+	name, extra := splitWord(rest)
+	name = strings.ToLower(name)
+	if _, ok := actions[name]; !ok {
+		return fmt.Errorf("unknown function: %s", name)
+	}
+	if extra != "" && extra[0] != '#' {
+		return fmt.Errorf("unexpected %s after %s", extra, name)
+	}
+	if name == "accept-line" && bytes.Equal(seq, Keys[Enter].Chars) {
+		// Enter accepts the line by definition, keep its handler
+		return nil
+	}
+	return e.bindAction(seq, name)
+}
+
+// bind attaches the given handler to the key with the given chars, or to the
+// given key sequence if it is not a single known key.
+func (e *Ed) bind(seq []byte, h func(*Ed, Key)) {
+	if k := find(seq); k.Code != Chars {
+		e.Handle(k.Code, h)
+	} else {
+		e.HandleSeq(seq, h)
+	}
+}
+
+// bindAction attaches the action with the given name to the key with the
+// given chars, or to the given key sequence if it is not a single known key.
+// Names starting with `macro:` play the named macro, see PlayMacro.
+func (e *Ed) bindAction(seq []byte, name string) error {
+	if strings.HasPrefix(name, macroPrefix) {
+		macro := strings.TrimPrefix(name, macroPrefix)
+		e.bind(seq, func(e *Ed, k Key) { e.PlayMacro(macro) })
+		return nil
+	}
+	if k := find(seq); k.Code != Chars {
+		return e.HandleAction(k.Code, name)
+	}
+	return e.HandleSeqAction(seq, name)
+}
+
+// play dispatches the given keys as if they were typed, unless keys are
+// being played already, so macros containing their own keys don't recurse.
+func (e *Ed) play(keys []Key) {
+	if e.playing {
+		return
+	}
+	e.playing = true
+	defer func() { e.playing = false }()
+	for _, k := range keys {
+		e.dispatch(k)
+	}
+}
+
+// parseKeyName parses a key name such as `Control-u`, `Meta-Rubout`, or `a`.
+func parseKeyName(s string) ([]byte, error) {
+	meta, ctrl := false, false
+	for {
+		lower := strings.ToLower(s)
+		if strings.HasPrefix(lower, "control-") && len(s) > 8 {
+			ctrl, s = true, s[8:]
+		} else if strings.HasPrefix(lower, "c-") && len(s) > 2 {
+			ctrl, s = true, s[2:]
+		} else if strings.HasPrefix(lower, "meta-") && len(s) > 5 {
+			meta, s = true, s[5:]
+		} else if strings.HasPrefix(lower, "m-") && len(s) > 2 {
+			meta, s = true, s[2:]
+		} else {
+			break
+		}
+	}
+
+	c, ok := keyNames[strings.ToLower(s)]
+	if !ok && len(s) != 1 {
+		return nil, fmt.Errorf("unknown key: %s", s)
+	} else if !ok {
+		c = s[0]
+	}
+	if ctrl {
+		c = control(c)
+	}
+	if meta {
+		return []byte{0x1b, c}, nil
+	}
+	return []byte{c}, nil
+}
+
+// unescapeKeys resolves the escape sequences used in quoted key sequences and
+// macros: `\C-x`, `\M-x`, `\e`, `\\`, `\"`, `\'`, `\a`, `\b`, `\d`, `\f`,
+// `\n`, `\r`, `\t`, `\v`, `\nnn` (octal), and `\xHH` (hex).
+func unescapeKeys(s string) []byte {
+	b := []byte{}
+	ctrl := false
+	emit := func(c byte) {
+		if ctrl {
+			c = control(c)
+			ctrl = false
+		}
+		b = append(b, c)
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			emit(s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; {
+		case (c == 'C' || c == 'M') && strings.HasPrefix(s[i+1:], "-") && i+2 < len(s):
+			if c == 'C' {
+				ctrl = true
+			} else {
+				b = append(b, 0x1b)
+			}
+			i++
+		case c == 'e':
+			emit(0x1b)
+		case c == 'a':
+			emit('\a')
+		case c == 'b':
+			emit('\b')
+		case c == 'd':
+			emit(0x7f)
+		case c == 'f':
+			emit('\f')
+		case c == 'n':
+			emit('\n')
+		case c == 'r':
+			emit('\r')
+		case c == 't':
+			emit('\t')
+		case c == 'v':
+			emit('\v')
+		case c >= '0' && c <= '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			n, _ := strconv.ParseUint(s[i:j], 8, 8)
+			emit(byte(n))
+			i = j - 1
+		case c == 'x' && i+1 < len(s) && isHex(s[i+1]):
+			j := i + 1
+			for j < len(s) && j < i+3 && isHex(s[j]) {
+				j++
+			}
+			n, _ := strconv.ParseUint(s[i+1:j], 16, 8)
+			emit(byte(n))
+			i = j - 1
+		default:
+			emit(c)
+		}
+	}
+	return b
+}
+
+// control returns the control char for the given char, e.g. 0x15 for `u`,
+// and 0x7f for `?`.
+func control(c byte) byte {
+	if c == '?' {
+		return 0x7f
+	}
+	return c & 0x1f
+}
+
+// closingQuote returns the index of the quote closing the quoted string at
+// the beginning of s, or -1.
+func closingQuote(s string, q byte) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == q {
+			return i
+		}
+	}
+	return -1
+}
+
+func splitWord(s string) (string, string) {
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i+1:])
+}
+
+func splitPair(s string, sep string) (string, string) {
+	i := strings.Index(s, sep)
+	if i < 0 {
+		return strings.TrimSpace(s), ""
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~") {
+		return path
+	}
+	i := strings.IndexByte(path, '/')
+	if i == -1 {
+		i = len(path)
+	}
+	home, ok := homeDir(path[1:i])
+	if !ok {
+		return path
+	}
+	return home + path[i:]
+}
+
+func isOn(s string) bool {
+	s = strings.ToLower(s)
+	return s == "" || s == "on" || s == "1"
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
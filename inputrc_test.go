@@ -0,0 +1,166 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInputrcBindings(t *testing.T) {
+	prompt, term := setup()
+	err := prompt.parseInputrc(strings.NewReader(`
+# comment
+Control-b: end-of-line
+"\C-xa": beginning-of-line
+Meta-Rubout: unix-line-discard
+"\C-xg": "git "
+`), "inputrc")
+	assert.NoError(t, err)
+
+	receive(term, "foo bar")
+	receive(term, key(CtrlX))
+	receive(term, "a")
+	assert.Equal(t, 0, prompt.Pos)
+	receive(term, key(CtrlB))
+	assert.Equal(t, 7, prompt.Pos)
+	receive(term, key(AltBackspace))
+	assert.Equal(t, "", prompt.Str())
+	receive(term, key(CtrlX))
+	receive(term, "g")
+	assert.Equal(t, "git ", prompt.Str())
+}
+
+func TestInputrcVariables(t *testing.T) {
+	prompt, term := setup()
+	err := prompt.parseInputrc(strings.NewReader(`
+set completion-ignore-case on
+set bell-style visible
+`), "inputrc")
+	assert.NoError(t, err)
+	assert.True(t, prompt.CompletionIgnoreCase)
+
+	receive(term, "RE")
+	prompt.CompleteNext([][]byte{[]byte("repo")})
+	assert.Equal(t, "repo", prompt.Str())
+
+	reset(term)
+	prompt.Bell()
+	assertOut(t, term, []string{"<flash-on><flash-off>"})
+}
+
+func TestInputrcEditingMode(t *testing.T) {
+	prompt, term := setup()
+	err := prompt.parseInputrc(strings.NewReader(`
+$if mode=emacs
+set editing-mode vi
+$endif
+$if mode=vi
+"\C-a": end-of-line
+$else
+"\C-a": beginning-of-line
+$endif
+`), "inputrc")
+	assert.NoError(t, err)
+	assert.Equal(t, "vi", prompt.EditingMode())
+
+	typeKeys(term, "foo\x1b0")
+	assert.Equal(t, 0, prompt.Pos)
+	receive(term, key(CtrlA))
+	assert.Equal(t, 3, prompt.Pos)
+}
+
+func TestInputrcTerm(t *testing.T) {
+	os.Setenv("TERM", "xterm-256color")
+	prompt, _ := setup()
+	err := prompt.parseInputrc(strings.NewReader(`
+$if term=xterm
+set bell-style none
+$endif
+$if term=screen
+set bell-style visible
+$endif
+`), "inputrc")
+	assert.NoError(t, err)
+	assert.Equal(t, "none", prompt.BellStyle)
+}
+
+func TestInputrcErrors(t *testing.T) {
+	prompt, _ := setup()
+	err := prompt.parseInputrc(strings.NewReader(`
+"\C-a": unknown-function
+Control-e end-of-line
+`), "inputrc")
+	assert.EqualError(t, err, "inputrc:2: unknown function: unknown-function\ninputrc:3: missing colon: Control-e end-of-line")
+}
+
+func TestInputrcIncludeCycle(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "led")
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	ioutil.WriteFile(a, []byte("$include "+b+"\n\"\\C-a\": end-of-line"), 0644)
+	ioutil.WriteFile(b, []byte("$include "+a), 0644)
+
+	prompt, term := setup()
+	err := prompt.LoadInputrc(a)
+	assert.EqualError(t, err, a+":1: "+b+":1: recursive include: "+a)
+	receive(term, "foo")
+	prompt.Return()
+	receive(term, key(CtrlA))
+	assert.Equal(t, 3, prompt.Pos)
+
+	ioutil.WriteFile(b, []byte("set bell-style none"), 0644)
+	assert.NoError(t, prompt.LoadInputrc(a))
+}
+
+func TestInputrcReread(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "led")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "inputrc")
+	ioutil.WriteFile(file, []byte(`"\C-x\C-r": re-read-init-file`), 0644)
+
+	prompt, term := setup()
+	assert.NoError(t, prompt.LoadInputrc(file))
+	ioutil.WriteFile(file, []byte(`"\C-a": end-of-line`), 0644)
+	receive(term, "foo")
+	receive(term, key(CtrlX))
+	receive(term, key(CtrlR))
+	prompt.Return()
+	receive(term, key(CtrlA))
+	assert.Equal(t, 3, prompt.Pos)
+
+	ioutil.WriteFile(file, []byte("\"\\C-x\\C-r\": re-read-init-file\n$include "+file+"\nC-b: foo\n"), 0644)
+	reset(term)
+	receive(term, key(CtrlX))
+	receive(term, key(CtrlR))
+	out := string(Deansi([]byte(term.out)))
+	assert.Contains(t, out, "<bell>")
+	assert.Contains(t, out, file+":2: recursive include: "+file)
+	assert.Contains(t, out, file+":3: unknown function: foo")
+}
+
+func TestInputrcTrailingText(t *testing.T) {
+	prompt, _ := setup()
+	assert.NoError(t, prompt.parseInputrc(strings.NewReader("C-a: end-of-line # comment"), "inputrc"))
+	assert.EqualError(t, prompt.parseInputrc(strings.NewReader("C-b: end-of-line foo"), "inputrc"), "inputrc:1: unexpected foo after end-of-line")
+}
+
+func TestUnescapeKeys(t *testing.T) {
+	assert.Equal(t, []byte{0x18, 0x12}, unescapeKeys(`\C-x\C-r`))
+	assert.Equal(t, []byte{0x1b, 'f'}, unescapeKeys(`\M-f`))
+	assert.Equal(t, []byte{0x1b, 0x7f, '\r', '"', 'A', 'B'}, unescapeKeys(`\e\d\r\"\101\x42`))
+}
+
+func TestParseKeyName(t *testing.T) {
+	b, err := parseKeyName("Control-u")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x15}, b)
+	b, _ = parseKeyName("M-Rubout")
+	assert.Equal(t, []byte{0x1b, 0x7f}, b)
+	b, _ = parseKeyName("TAB")
+	assert.Equal(t, []byte{'\t'}, b)
+	_, err = parseKeyName("Foo")
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package led
 
 import (
 	"bytes"
+	"unicode/utf8"
 )
 
 type reader interface {
@@ -47,34 +48,74 @@ const (
 	Down
 	Right
 	Left
+	CtrlY
+	AltY
+	CtrlX
+	CtrlUnderscore
+	CtrlR
+	AltF
+	AltB
+	AltD
+	AltBackspace
+	AltU
+	AltL
+	AltC
+	AltT
+	AltLt
+	AltGt
 )
 
 // Keys defines known keys
 var Keys = map[int]Key{
-	CtrlA:     {CtrlA, []byte{0x1}, "Ctrl-A"},
-	CtrlB:     {CtrlB, []byte{0x2}, "Ctrl-B"},
-	CtrlC:     {CtrlC, []byte{0x3}, "Ctrl-C"},
-	CtrlD:     {CtrlD, []byte{0x4}, "Ctrl-D"},
-	CtrlE:     {CtrlE, []byte{0x5}, "Ctrl-E"},
-	CtrlF:     {CtrlF, []byte{0x6}, "Ctrl-F"},
-	CtrlH:     {CtrlH, []byte{0x8}, "Ctrl-H"},
-	Tab:       {Tab, []byte{0x9}, "Tab"},
-	CtrlK:     {CtrlK, []byte{0x0b}, "Ctrl-K"},
-	CtrlL:     {CtrlL, []byte{0x0c}, "Ctrl-L"},
-	Enter:     {Enter, []byte{0x0d}, "Enter"},
-	CtrlN:     {CtrlN, []byte{0x0e}, "Ctrl-N"},
-	CtrlP:     {CtrlP, []byte{0x10}, "Ctrl-P"},
-	CtrlT:     {CtrlT, []byte{0x14}, "Ctrl-T"},
-	CtrlU:     {CtrlU, []byte{0x15}, "Ctrl-U"},
-	CtrlW:     {CtrlW, []byte{0x17}, "Ctrl-W"},
-	Esc:       {Esc, []byte{0x1b}, "Esc"},
-	Backspace: {Backspace, []byte{0x7f}, "Backspace"},
-	Delete:    {Delete, []byte{0x1b, 0x5b, 0x33, 0x7E}, "Delete"}, // \x1b[3~
-	ShiftTab:  {ShiftTab, []byte{0x1b, 0x5b, 0x5a}, "Shift-Tab"},
-	Up:        {Up, []byte{0x1b, 0x5b, 0x41}, "Up"},
-	Down:      {Down, []byte{0x1b, 0x5b, 0x42}, "Down"},
-	Right:     {Right, []byte{0x1b, 0x5b, 0x43}, "Right"},
-	Left:      {Left, []byte{0x1b, 0x5b, 0x44}, "Left"},
+	CtrlA:          {CtrlA, []byte{0x1}, "Ctrl-A"},
+	CtrlB:          {CtrlB, []byte{0x2}, "Ctrl-B"},
+	CtrlC:          {CtrlC, []byte{0x3}, "Ctrl-C"},
+	CtrlD:          {CtrlD, []byte{0x4}, "Ctrl-D"},
+	CtrlE:          {CtrlE, []byte{0x5}, "Ctrl-E"},
+	CtrlF:          {CtrlF, []byte{0x6}, "Ctrl-F"},
+	CtrlH:          {CtrlH, []byte{0x8}, "Ctrl-H"},
+	Tab:            {Tab, []byte{0x9}, "Tab"},
+	CtrlK:          {CtrlK, []byte{0x0b}, "Ctrl-K"},
+	CtrlL:          {CtrlL, []byte{0x0c}, "Ctrl-L"},
+	Enter:          {Enter, []byte{0x0d}, "Enter"},
+	CtrlN:          {CtrlN, []byte{0x0e}, "Ctrl-N"},
+	CtrlP:          {CtrlP, []byte{0x10}, "Ctrl-P"},
+	CtrlT:          {CtrlT, []byte{0x14}, "Ctrl-T"},
+	CtrlU:          {CtrlU, []byte{0x15}, "Ctrl-U"},
+	CtrlW:          {CtrlW, []byte{0x17}, "Ctrl-W"},
+	Esc:            {Esc, []byte{0x1b}, "Esc"},
+	Backspace:      {Backspace, []byte{0x7f}, "Backspace"},
+	Delete:         {Delete, []byte{0x1b, 0x5b, 0x33, 0x7E}, "Delete"}, // \x1b[3~
+	ShiftTab:       {ShiftTab, []byte{0x1b, 0x5b, 0x5a}, "Shift-Tab"},
+	Up:             {Up, []byte{0x1b, 0x5b, 0x41}, "Up"},
+	Down:           {Down, []byte{0x1b, 0x5b, 0x42}, "Down"},
+	Right:          {Right, []byte{0x1b, 0x5b, 0x43}, "Right"},
+	Left:           {Left, []byte{0x1b, 0x5b, 0x44}, "Left"},
+	CtrlY:          {CtrlY, []byte{0x19}, "Ctrl-Y"},
+	AltY:           {AltY, []byte{0x1b, 'y'}, "Alt-Y"},
+	CtrlX:          {CtrlX, []byte{0x18}, "Ctrl-X"},
+	CtrlUnderscore: {CtrlUnderscore, []byte{0x1f}, "Ctrl-_"},
+	CtrlR:          {CtrlR, []byte{0x12}, "Ctrl-R"},
+	AltF:           {AltF, []byte{0x1b, 'f'}, "Alt-F"},
+	AltB:           {AltB, []byte{0x1b, 'b'}, "Alt-B"},
+	AltD:           {AltD, []byte{0x1b, 'd'}, "Alt-D"},
+	AltBackspace:   {AltBackspace, []byte{0x1b, 0x7f}, "Alt-Backspace"},
+	AltU:           {AltU, []byte{0x1b, 'u'}, "Alt-U"},
+	AltL:           {AltL, []byte{0x1b, 'l'}, "Alt-L"},
+	AltC:           {AltC, []byte{0x1b, 'c'}, "Alt-C"},
+	AltT:           {AltT, []byte{0x1b, 't'}, "Alt-T"},
+	AltLt:          {AltLt, []byte{0x1b, '<'}, "Alt-<"},
+	AltGt:          {AltGt, []byte{0x1b, '>'}, "Alt->"},
+}
+
+// Seq returns the concatenated chars of the given keys, e.g. for binding a
+// key sequence using Ed.HandleSeq.
+func Seq(keys ...int) []byte {
+	b := []byte{}
+	for _, k := range keys {
+		b = append(b, Keys[k].Chars...)
+	}
+	return b
 }
 
 // Read returns a channel for reading keys. Terminates on ctrl-d.
@@ -101,11 +142,36 @@ func Read(tty reader) chan Key {
 	return keys
 }
 
+// ParseKeys splits the given chars into keys, e.g. for replaying them.
+// Known keys are matched greedily, Esc followed by a printable char is kept
+// as one key (as sent by the terminal for Alt and the char), any other chars
+// are split into single char keys.
+func ParseKeys(b []byte) []Key {
+	keys := []Key{}
+	for len(b) > 0 {
+		k := Key{Code: Chars}
+		for _, known := range Keys {
+			if bytes.HasPrefix(b, known.Chars) && len(known.Chars) > len(k.Chars) {
+				k = known
+			}
+		}
+		if k.Code == Esc && len(b) > 1 && b[1] >= ' ' && b[1] < 0x7f {
+			k = Key{Code: Chars, Chars: dup(b[:2])}
+		} else if k.Code == Chars {
+			_, n := utf8.DecodeRune(b)
+			k.Chars = dup(b[:n])
+		}
+		keys = append(keys, k)
+		b = b[len(k.Chars):]
+	}
+	return keys
+}
+
 func find(b []byte) Key {
 	for _, k := range Keys {
 		if bytes.Equal(k.Chars, b) {
 			return k
 		}
 	}
-	return Key{Code: Chars, Chars: b}
+	return Key{Code: Chars, Chars: dup(b)}
 }
@@ -0,0 +1,103 @@
+package led
+
+// Kinds of commands that behave differently when repeated.
+const (
+	cmdKill = iota + 1
+	cmdYank
+	cmdInsert
+	cmdComplete
+)
+
+// ringSize is the maximum number of entries kept in the kill ring.
+const ringSize = 20
+
+// ring represents an Emacs style kill ring. It also remembers the position
+// and length of the most recently yanked chars.
+type ring struct {
+	strs [][]byte
+	curr int
+	pos  int
+	len  int
+}
+
+// push adds the given chars as the most recent entry.
+func (r *ring) push(b []byte) {
+	r.strs = append(r.strs, b)
+	if len(r.strs) > ringSize {
+		r.strs = r.strs[1:]
+	}
+	r.curr = len(r.strs) - 1
+}
+
+// add adds the given chars to the most recent entry, prepending them if the
+// given direction is Back, and appending them otherwise.
+func (r *ring) add(b []byte, dir int) {
+	top := r.strs[len(r.strs)-1]
+	if dir == Back {
+		top = concat(dup(b), top)
+	} else {
+		top = concat(dup(top), b)
+	}
+	r.strs[len(r.strs)-1] = top
+	r.curr = len(r.strs) - 1
+}
+
+// rotate moves to the next older entry, wrapping around, and returns it.
+func (r *ring) rotate() []byte {
+	r.curr--
+	if r.curr < 0 {
+		r.curr = len(r.strs) - 1
+	}
+	return r.strs[r.curr]
+}
+
+// Yank inserts the most recently killed chars at the current cursor
+// position.
+func (e *Ed) Yank() {
+	if len(e.kills.strs) == 0 {
+		return
+	}
+	e.kills.curr = len(e.kills.strs) - 1
+	b := e.kills.strs[e.kills.curr]
+	e.save()
+	e.kills.pos, e.kills.len = e.Pos, len(b)
+	e.Insert(dup(b))
+	e.mark(cmdYank)
+}
+
+// YankPop replaces the chars inserted by the previous Yank or YankPop with
+// the next older entry from the kill ring. It does nothing unless the
+// previous command was a yank.
+func (e *Ed) YankPop() {
+	if !e.follows(cmdYank) || len(e.kills.strs) < 2 {
+		return
+	}
+	pos, l := e.kills.pos, e.kills.len
+	b := e.kills.rotate()
+	e.kills.len = len(b)
+	e.save()
+	e.redraw(concat(dup(e.Chars[:pos]), b, e.Chars[pos+l:]), pos+len(b))
+	e.mark(cmdYank)
+}
+
+// kill adds the given chars to the kill ring. Consecutive kills are added
+// to the same entry.
+func (e *Ed) kill(b []byte, dir int) {
+	if e.follows(cmdKill) && len(e.kills.strs) > 0 {
+		e.kills.add(b, dir)
+	} else if len(b) > 0 {
+		e.kills.push(b)
+	}
+	e.mark(cmdKill)
+}
+
+// follows returns true if the given kind of command has been run by the
+// previous (or current) key.
+func (e *Ed) follows(cmd int) bool {
+	return e.cmd == cmd && e.cmdSeq >= e.seq-1
+}
+
+// mark records the given kind of command for the current key.
+func (e *Ed) mark(cmd int) {
+	e.cmd, e.cmdSeq = cmd, e.seq
+}
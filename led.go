@@ -2,82 +2,335 @@ package led
 
 import (
 	"bytes"
+	"errors"
+	"strings"
 	"time"
 )
 
 // Forw/Back - directions
 // Hist/Comp/Sugg - modes for cycling through sets
+// Prefix/Substr - modes for searching the history
 const (
 	Forw int = iota
 	Back
 	Hist
 	Comp
 	Sugg
+	Prefix
+	Substr
 )
 
 var space = []byte{' '}
 
-// NewReadline creates a line editor that resembles most of Linenoise's functionality
+// NewReadline creates a line editor that resembles most of Linenoise's
+// functionality. Tab and Shift-Tab complete the line using the editor's
+// Completer, if any (see AutoComplete).
 func NewReadline(led string, t ...Iterm) *Ed {
 	e := NewEd(led, t...)
-	e.Handle(Chars, func(e *Ed, k Key) { e.Insert(k.Chars) })
-	e.Handle(CtrlA, func(e *Ed, k Key) { e.Return() })
-	e.Handle(CtrlB, func(e *Ed, k Key) { e.Left() })
-	e.Handle(CtrlC, func(e *Ed, k Key) { e.Discard() })
-	e.Handle(CtrlD, func(e *Ed, k Key) { e.Delete() })
-	e.Handle(CtrlE, func(e *Ed, k Key) { e.End() })
-	e.Handle(CtrlF, func(e *Ed, k Key) { e.Right() })
-	e.Handle(CtrlK, func(e *Ed, k Key) { e.DeleteFromCursor() })
-	e.Handle(CtrlT, func(e *Ed, k Key) { e.Transpose() })
-	e.Handle(CtrlU, func(e *Ed, k Key) { e.Reset() })
-	e.Handle(CtrlW, func(e *Ed, k Key) { e.BackWord() })
-	e.Handle(Enter, func(e *Ed, k Key) { e.Newline() })
-	e.Handle(Backspace, func(e *Ed, k Key) { e.Back() })
-	e.Handle(Delete, func(e *Ed, k Key) { e.Delete() })
-	e.Handle(Left, func(e *Ed, k Key) { e.Left() })
-	e.Handle(Right, func(e *Ed, k Key) { e.Right() })
+	bindEmacs(e)
 	return e
 }
 
+func bindEmacs(e *Ed) {
+	e.HandleAction(Chars, "self-insert")
+	e.HandleAction(CtrlA, "beginning-of-line")
+	e.HandleAction(CtrlB, "backward-char")
+	e.HandleAction(CtrlC, "discard-line")
+	e.HandleAction(CtrlD, "delete-char")
+	e.HandleAction(CtrlE, "end-of-line")
+	e.HandleAction(CtrlF, "forward-char")
+	e.HandleAction(CtrlH, "backward-delete-char")
+	e.HandleAction(CtrlK, "kill-line")
+	e.HandleAction(CtrlL, "clear-screen")
+	e.HandleAction(CtrlT, "transpose-chars")
+	e.HandleAction(CtrlU, "unix-line-discard")
+	e.HandleAction(CtrlW, "unix-word-rubout")
+	e.HandleAction(CtrlY, "yank")
+	e.HandleAction(AltY, "yank-pop")
+	e.HandleAction(CtrlUnderscore, "undo")
+	e.HandleSeqAction(Seq(CtrlX, CtrlU), "undo")
+	e.HandleSeqAction(append(Seq(CtrlX), '('), "start-kbd-macro")
+	e.HandleSeqAction(append(Seq(CtrlX), ')'), "end-kbd-macro")
+	e.HandleSeqAction(append(Seq(CtrlX), 'e'), "call-last-kbd-macro")
+	e.HandleSeqAction(append(Seq(CtrlX), '?'), "list-bindings")
+	e.HandleSeqAction(append(Seq(CtrlX), 'k'), "describe-key")
+	e.HandleAction(Enter, "newline")
+	e.HandleAction(Tab, "complete")
+	e.HandleAction(ShiftTab, "complete-backward")
+	e.HandleAction(Backspace, "backward-delete-char")
+	e.HandleAction(Delete, "delete-char")
+	e.HandleAction(Left, "backward-char")
+	e.HandleAction(Right, "forward-char")
+	e.HandleAction(Up, "previous-history")
+	e.HandleAction(Down, "next-history")
+	e.HandleAction(CtrlP, "previous-history")
+	e.HandleAction(CtrlN, "next-history")
+	e.HandleAction(AltLt, "beginning-of-history")
+	e.HandleAction(AltGt, "end-of-history")
+	e.HandleAction(AltF, "forward-word")
+	e.HandleAction(AltB, "backward-word")
+	e.HandleAction(AltD, "kill-word")
+	e.HandleAction(AltBackspace, "backward-kill-word")
+	e.HandleAction(AltU, "upcase-word")
+	e.HandleAction(AltL, "downcase-word")
+	e.HandleAction(AltC, "capitalize-word")
+	e.HandleAction(AltT, "transpose-words")
+}
+
 // NewEd creates a line editor (Ed) without any handlers attached. See
 // NewReadline for a line editor that resembles (most of) Linenoise's
 // functionality.
 func NewEd(led string, t ...Iterm) *Ed {
 	return &Ed{
-		term:      StartTerm(t...),
-		handlers:  map[int]func(*Ed, Key){},
-		Prompt:    []byte(led),
-		Pos:       0,
-		Chars:     []byte{},
-		Suggested: []byte{},
+		term:                 StartTerm(t...),
+		handlers:             map[int]func(*Ed, Key){},
+		seqs:                 map[string]func(*Ed, Key){},
+		names:                map[int]string{},
+		seqNames:             map[string]string{},
+		Macros:               map[string]*Macro{},
+		Prompt:               []byte(led),
+		Pos:                  0,
+		Chars:                []byte{},
+		Suggested:            []byte{},
+		Hist:                 NewHistory(),
+		kills:                &ring{},
+		HistoryMode:          Hist,
+		PromptColor:          Reset,
+		SuggestColor:         Green,
+		AutoSuggestColor:     Dim,
+		RejectColor:          Red,
+		CompletionQueryItems: 100,
+		AsyncTimeout:         5 * time.Second,
+		Spinner:              []string{"|", "/", "-", "\\"},
+		events:               make(chan func(*Ed)),
+		done:                 make(chan struct{}),
+		arg:                  1,
 	}
 }
 
 // Ed represents the line editor
 type Ed struct {
-	term      *Term
-	handlers  map[int]func(*Ed, Key)
-	Prompt    []byte
-	Pos       int
-	Chars     []byte
-	Suggested []byte
-	list      *List
-}
-
-// Handle attaches a handler for a key
+	term                 *Term
+	handlers             map[int]func(*Ed, Key)
+	seqs                 map[string]func(*Ed, Key)
+	names                map[int]string
+	seqNames             map[string]string
+	pending              []byte
+	Prompt               []byte
+	Pos                  int
+	Chars                []byte
+	Suggested            []byte
+	Hist                 *History
+	HistoryMode          int
+	HistoryMatcher       Matcher
+	HistoryExpand        bool
+	HistoryVerify        bool
+	Macros               map[string]*Macro
+	Completer            Completer
+	CompletionStyle      int
+	CompletionQueryItems int
+	BellStyle            string
+	PromptColor          int
+	SuggestColor         int
+	SuggestMatcher       Matcher
+	Suggester            Suggester
+	AutoSuggest          bool
+	AutoSuggestColor     int
+	RejectColor          int
+	CompletionIgnoreCase bool
+	CompletionMatcher    Matcher
+	AsyncTimeout         time.Duration
+	Spinner              []string
+	list                 *List
+	comp                 *completion
+	menu                 *menu
+	modal                func(*Ed, Key) bool
+	pager                []string
+	events               chan func(*Ed)
+	done                 chan struct{}
+	job                  *job
+	suggestedFor         []byte
+	nav                  *nav
+	kills                *ring
+	vi                   *vi
+	undos                []snapshot
+	redos                []snapshot
+	seq                  int
+	arg                  int
+	args                 []byte
+	typed                []Key
+	macro                *Macro
+	lastMacro            *Macro
+	playing              bool
+	cmd                  int
+	cmdSeq               int
+	inputrc              string
+	including            []string
+	config               string
+	describing           bool
+}
+
+// Handle attaches a handler for a key. See HandleAction for attaching a
+// named action.
 func (e *Ed) Handle(key int, handler func(*Ed, Key)) {
 	e.handlers[key] = handler
+	e.names[key] = ""
+}
+
+// HandleSeq attaches a handler for a sequence of keys, given as the
+// concatenated chars of the keys (see Seq), e.g. Ctrl-X Ctrl-U. Sequences take
+// precedence over handlers for single keys.
+func (e *Ed) HandleSeq(seq []byte, handler func(*Ed, Key)) {
+	e.seqs[string(seq)] = handler
+	e.seqNames[string(seq)] = ""
+}
+
+// EditingMode returns the name of the editor's keymap, "emacs" or "vi".
+func (e *Ed) EditingMode() string {
+	if e.vi != nil {
+		return "vi"
+	}
+	return "emacs"
+}
+
+// SetEditingMode switches to the keymap with the given name, "emacs" (see
+// NewReadline) or "vi" (see NewViReadline). Handlers attached before are
+// discarded.
+func (e *Ed) SetEditingMode(mode string) error {
+	if mode != "emacs" && mode != "vi" {
+		return errors.New("unknown editing mode: " + mode)
+	}
+	if mode == e.EditingMode() && len(e.handlers) > 0 {
+		return nil
+	}
+	e.handlers = map[int]func(*Ed, Key){}
+	e.seqs = map[string]func(*Ed, Key){}
+	e.names = map[int]string{}
+	e.seqNames = map[string]string{}
+	e.vi = nil
+	if mode == "vi" {
+		bindVi(e)
+	} else {
+		bindEmacs(e)
+	}
+	return nil
+}
+
+// Bell signals the user that something went wrong according to the
+// BellStyle: "audible" (the default) rings the terminal bell, "visible"
+// flashes the screen, and "none" does nothing.
+func (e *Ed) Bell() {
+	switch e.BellStyle {
+	case "none":
+	case "visible":
+		e.term.Flash()
+	default:
+		e.term.Bell()
+	}
 }
 
 // Run runs the editor
 func (e *Ed) Run() {
 	e.Refresh()
-	for k := range e.term.Read() {
-		if e.handlers[k.Code] != nil {
-			e.handlers[k.Code](e, k)
+	keys := e.term.Read()
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok {
+				e.cancelJob()
+				close(e.done)
+				e.Stop()
+				return
+			}
+			e.dispatch(k)
+		case f := <-e.events:
+			f(e)
+		}
+	}
+}
+
+func (e *Ed) dispatch(k Key) {
+	e.cancelJob()
+	e.seq++
+	e.typed = append(e.typed, k)
+	if e.modal != nil && e.modal(e, k) {
+		e.typed = nil
+		return
+	}
+	if !e.describing && e.readArg(k) {
+		return
+	}
+	h := e.handler(k)
+	if e.describing && (h != nil || len(e.pending) == 0) {
+		e.describing = false
+		e.describe(e.typed, h != nil)
+		e.typed = nil
+		return
+	}
+	if h == nil {
+		if len(e.pending) == 0 {
+			e.typed = nil
 		}
+		return
+	}
+
+	typed := e.typed
+	e.typed = nil
+	recording := e.macro != nil && !e.controlsMacro(typed)
+	e.takeArg()
+	run := true
+	if e.accepts(typed) {
+		run = e.expandOnAccept()
+		if run {
+			e.hideSuggestion()
+		}
+	}
+	if run {
+		h(e, k)
+	}
+	e.arg = 1
+	e.autoSuggest()
+	if recording && e.macro != nil {
+		e.macro.Keys = append(e.macro.Keys, typed...)
 	}
-	e.Stop()
+}
+
+// controlsMacro returns whether the given keys run a macro action, which is
+// not recorded.
+func (e *Ed) controlsMacro(keys []Key) bool {
+	_, name := e.actionName(keys)
+	return name == "start-kbd-macro" || name == "end-kbd-macro" || name == "call-last-kbd-macro"
+}
+
+// accepts returns whether the given keys accept the line, i.e. run the
+// newline or accept-line action, or a custom handler attached to Enter.
+func (e *Ed) accepts(keys []Key) bool {
+	_, name := e.actionName(keys)
+	custom := name == "" && len(keys) == 1 && keys[0].Code == Enter
+	return custom || name == "newline" || name == "accept-line"
+}
+
+// handler returns the handler for the given key, or for the key sequence
+// completed by it. If the key starts a known sequence it is remembered, and
+// nil is returned. Keys that do not complete a started sequence are
+// discarded.
+func (e *Ed) handler(k Key) func(*Ed, Key) {
+	seq := concat(dup(e.pending), k.Chars)
+	if h, ok := e.seqs[string(seq)]; ok {
+		e.pending = nil
+		return h
+	}
+	for s := range e.seqs {
+		if len(s) > len(seq) && strings.HasPrefix(s, string(seq)) {
+			e.pending = seq
+			return nil
+		}
+	}
+	if len(e.pending) > 0 {
+		e.pending = nil
+		return nil
+	}
+	return e.handlers[k.Code]
 }
 
 // Pause pauses the editor, should be used before outputting text to the
@@ -91,93 +344,150 @@ func (e *Ed) Resume() {
 	e.term.Resume()
 }
 
-// Left moves the cursor one char to the left.
+// Left moves the cursor one char, or the number of chars given as a numeric
+// argument (see Arg), to the left.
 func (e *Ed) Left() {
-	if e.Pos > 0 {
-		e.MoveCursor(1, Back)
-	}
+	e.moveBy(-e.Arg())
 }
 
-// Right moves the cursor one char to the right.
+// Right moves the cursor one char, or the number of chars given as a numeric
+// argument, to the right. At the end of the line it accepts the suggestion,
+// if any.
 func (e *Ed) Right() {
-	if e.Pos < len(e.Chars) {
-		e.MoveCursor(1, Forw)
+	if !e.AcceptSuggestion() {
+		e.moveBy(e.Arg())
 	}
 }
 
 // Append appends the given chars at the end of the line.
 func (e *Ed) Append(b []byte) {
+	e.save()
 	e.Chars = append(e.Chars, b...)
 	e.Pos = len(e.Chars) - 1
 	e.Write(b)
 	e.update()
 }
 
-// Insert inserts the given chars at the current cursor position.
+// Insert inserts the given chars at the current cursor position. Consecutive
+// inserts are undone in one step.
 func (e *Ed) Insert(b []byte) {
+	if !e.follows(cmdInsert) {
+		e.save()
+	}
 	e.Chars = insert(e.Chars, b, e.Pos)
 	e.Write(b)
 	e.Pos += len(b)
 	e.update()
+	e.mark(cmdInsert)
 }
 
-// Reject rejects the given chars by printing them at the current
-// cursor position in red, and removing them after 100 milliseconds.
+// Reject rejects the given chars by printing them at the current cursor
+// position in the RejectColor (red), and removing them after 100
+// milliseconds.
 func (e *Ed) Reject(chars []byte) {
-	e.term.Write(Colored(Red, chars))
+	e.term.Write(Colored(e.RejectColor, chars))
 	time.Sleep(100 * time.Millisecond)
 	e.SetCursor()
 	e.clear()
 }
 
-// Back removes one char before the current cursor position.
+// Back removes one char, or the number of chars given as a numeric argument,
+// before the current cursor position.
 func (e *Ed) Back() {
-	if e.Pos == 0 {
-		return
-	}
-
-	e.MoveCursor(1, Back)
-	e.Chars = delete(e.Chars, e.Pos, 1)
-	e.Del()
-	e.update()
+	e.deleteBy(-e.Arg())
 }
 
-// BackWord removes one word before the current cursor position.
+// BackWord removes one word, or the number of words given as a numeric
+// argument, before the current cursor position, and adds them to the kill
+// ring.
 func (e *Ed) BackWord() {
+	for i := 0; i < max(e.Arg(), 1); i++ {
+		e.backWord()
+	}
+}
+
+func (e *Ed) backWord() {
 	if e.Pos == 0 {
 		return
 	}
 
-	w := lastWord(e.Chars[:e.Pos], true)
+	start := 0
+	if toks := Tokenize(e.Chars[:e.Pos]); len(toks) > 0 {
+		start = toks[len(toks)-1].Start
+	}
+	w := dup(e.Chars[start:e.Pos])
+	e.save()
+	e.kill(w, Back)
 	e.MoveCursor(len(w), Back)
 	e.Chars = delete(e.Chars, e.Pos, len(w))
 	e.Del(len(w))
 	e.update()
 }
 
-// Delete removes one char after the current cursor position.
+// Delete removes one char, or the number of chars given as a numeric
+// argument, after the current cursor position.
 func (e *Ed) Delete() {
-	if e.Pos == len(e.Chars) {
+	e.deleteBy(e.Arg())
+}
+
+// deleteBy removes the given number of chars after the current cursor
+// position, or before it if negative.
+func (e *Ed) deleteBy(i int) {
+	n := min(i, len(e.Chars)-e.Pos)
+	if i < 0 {
+		n = min(-i, e.Pos)
+	}
+	if n == 0 {
 		return
 	}
 
-	e.Chars = delete(e.Chars, e.Pos, 1)
-	e.Del()
+	e.save()
+	if i < 0 {
+		e.MoveCursor(n, Back)
+	}
+	e.Chars = delete(e.Chars, e.Pos, n)
+	e.Del(n)
 	e.update()
 }
 
+// moveBy moves the cursor by the given number of chars to the right, or to
+// the left if negative.
+func (e *Ed) moveBy(i int) {
+	if i < 0 && e.Pos > 0 {
+		e.MoveCursor(min(-i, e.Pos), Back)
+	} else if i > 0 && e.Pos < len(e.Chars) {
+		n := min(i, len(e.Chars)-e.Pos)
+		e.Pos += n
+		e.term.MoveCursor(n, Forw)
+	}
+}
+
 // DeleteFromCursor deletes all chars from the current cursor position to the
-// end of the line.
+// end of the line, and adds them to the kill ring.
 func (e *Ed) DeleteFromCursor() {
 	if e.Pos == len(e.Chars) {
 		return
 	}
 
+	e.save()
+	e.kill(dup(e.Chars[e.Pos:]), Forw)
 	e.Chars = delete(e.Chars, e.Pos, len(e.Chars)-e.Pos)
 	e.clear()
 	e.update()
 }
 
+// DeleteToCursor deletes all chars from the beginning of the line to the
+// current cursor position, and adds them to the kill ring.
+func (e *Ed) DeleteToCursor() {
+	if e.Pos == 0 {
+		return
+	}
+
+	e.save()
+	e.kill(dup(e.Chars[:e.Pos]), Back)
+	e.redraw(dup(e.Chars[e.Pos:]), 0)
+}
+
 // Transpose transposes the char before the cursor position with the one on the
 // cursor position, and moves the cursor one char to the right, if possible. If
 // the cursor is at the beginning of the line it transposes the current char
@@ -195,6 +505,7 @@ func (e *Ed) Transpose() {
 		offset = 1
 	}
 
+	e.save()
 	e.SetCursor(e.Pos - offset)
 	e.Chars = swap(e.Chars, e.Pos, e.Pos+1)
 	e.Write(e.Chars[e.Pos:])
@@ -220,7 +531,8 @@ func (e *Ed) CompletePrev(strs [][]byte) {
 }
 
 // Complete displays the previous or next completion from the given slice
-// depending on the given direction.
+// depending on the given direction. Completions replace the word under or
+// left of the cursor, keeping the rest of the line.
 func (e *Ed) Complete(strs [][]byte, dir int) {
 	e.cycle(strs, Comp, dir)
 }
@@ -236,40 +548,104 @@ func (e *Ed) HistoryPrev(strs [][]byte) {
 }
 
 // History displays the previous or next line from the given slice
-// depending on the given direction.
+// depending on the given direction. The line being typed is kept as a virtual
+// last line, and edits made to recalled lines are remembered until the editor
+// is reset. Rings the bell when there is no line to move to.
+//
+// Lines are matched according to the editor's HistoryMode: Hist matches
+// lines by the first word of the current line, Prefix by the whole text left
+// of the cursor (keeping the cursor in place), and Substr by lines containing
+// the text left of the cursor. If set, the HistoryMatcher is used to match
+// lines instead, e.g. FuzzyMatcher lists the best matching lines first.
 func (e *Ed) History(strs [][]byte, dir int) {
-	e.cycle(strs, Hist, dir)
+	e.navigate(strs, func(n *nav) []byte { return n.move(dir) })
+}
+
+// HistoryFirst displays the first (oldest) line from the given slice,
+// regardless of the HistoryMode. Moving through the history continues from
+// there, including all lines.
+func (e *Ed) HistoryFirst(strs [][]byte) {
+	if e.nav == nil || !e.nav.eq(strs, e.HistoryMode) {
+		e.nav = newNav(strs, e.Chars, e.Pos, e.HistoryMode, e.HistoryMatcher)
+	}
+	if !e.nav.all {
+		e.nav.save(e.Chars)
+		e.nav = allNav(strs, e.nav.draft(), e.HistoryMode)
+	}
+	e.navigate(strs, func(n *nav) []byte { return n.jump(0) })
+}
+
+// HistoryLast returns to the line that was being typed before navigating
+// the history.
+func (e *Ed) HistoryLast(strs [][]byte) {
+	e.navigate(strs, func(n *nav) []byte { return n.jump(len(n.lines) - 1) })
+}
+
+func (e *Ed) navigate(strs [][]byte, move func(*nav) []byte) {
+	if e.nav == nil || !e.nav.eq(strs, e.HistoryMode) {
+		e.nav = newNav(strs, e.Chars, e.Pos, e.HistoryMode, e.HistoryMatcher)
+	}
+	e.nav.save(e.Chars)
+	b := move(e.nav)
+	if b == nil {
+		e.Bell()
+		return
+	}
+	e.Set(b)
+	if pos := e.nav.cursor(b); pos != e.Pos {
+		e.SetCursor(pos)
+	}
 }
 
 func (e *Ed) cycle(strs [][]byte, mode int, dir int) {
-	c := NewList(strs, e.Chars, mode)
+	c := NewList(strs, e.Chars[:e.Pos], mode)
+	if mode == Comp {
+		c.Matcher = e.completionMatcher()
+	}
 	if e.list == nil || !e.list.eq(c) {
 		e.list = c
 	}
 
-	b := []byte{}
+	t := Token{End: len(e.Chars)}
 	if mode == Comp {
-		b = trimLastWord(e.Chars)
-		if len(b) > 0 {
-			b = concat(b, space)
-		}
+		t = tokenAt(e.Chars, e.Pos)
 	}
 
+	var w []byte
 	if dir == Back {
-		b = concat(b, e.list.Prev())
+		w = e.list.Prev()
 	} else {
-		b = concat(b, e.list.Next())
+		w = e.list.Next()
 	}
+	if len(w) > 0 && mode == Comp {
+		w = []byte(quoteWord(string(w), t.Quote))
+	}
+	b := concat(dup(e.Chars[:t.Start]), w)
+	line := concat(dup(b), e.Chars[t.End:])
 
-	if bytes.Equal(b, e.Chars) && mode == Comp {
+	switch {
+	case bytes.Equal(line, e.Chars) && mode == Comp:
 		e.list = nil
-	} else {
+	case t.End == len(e.Chars):
 		e.Set(b)
+	default:
+		e.save()
+		e.redraw(line, len(b))
+	}
+}
+
+// completionMatcher returns the CompletionMatcher, or IgnoreCaseMatcher if
+// CompletionIgnoreCase is set.
+func (e *Ed) completionMatcher() Matcher {
+	if e.CompletionMatcher == nil && e.CompletionIgnoreCase {
+		return IgnoreCaseMatcher
 	}
+	return e.CompletionMatcher
 }
 
-// Suggest appends the first matching suggestion from the given slice in green
-// after the current cursor position.
+// Suggest appends the first matching suggestion from the given slice in the
+// SuggestColor (green) after the current cursor position. See Suggester for
+// suggesting automatically while typing.
 func (e *Ed) Suggest(str []byte) {
 	if e.Pos == 0 {
 		e.clearLine()
@@ -282,7 +658,7 @@ func (e *Ed) Suggest(str []byte) {
 	e.Suggested = s
 	if len(s) > 0 {
 		e.clear()
-		e.Write(concat(e.Chars[e.Pos:], Colored(Green, e.Suggested)))
+		e.Write(concat(e.Chars[e.Pos:], Colored(e.SuggestColor, e.Suggested)))
 		e.SetCursor()
 	}
 }
@@ -297,13 +673,21 @@ func (e *Ed) Return() {
 	e.SetCursor(0)
 }
 
-// End moves the cursor to the end of the line.
+// End moves the cursor to the end of the line, or accepts the suggestion if
+// it is already there.
 func (e *Ed) End() {
-	e.SetCursor(len(e.Chars))
+	if !e.AcceptSuggestion() {
+		e.SetCursor(len(e.Chars))
+	}
 }
 
 // Set sets the content of the editor to the given line.
 func (e *Ed) Set(b []byte) {
+	e.save()
+	e.set(b)
+}
+
+func (e *Ed) set(b []byte) {
 	e.SetCursor(0)
 	e.clear()
 	e.Chars = b
@@ -312,9 +696,36 @@ func (e *Ed) Set(b []byte) {
 	e.update()
 }
 
+// redraw sets the content of the editor to the given line, and the cursor to
+// the given position.
+func (e *Ed) redraw(b []byte, pos int) {
+	e.set(b)
+	e.SetCursor(pos)
+}
+
+// ClearScreen clears the screen, and redraws the line at the top.
+func (e *Ed) ClearScreen() {
+	e.term.ClearScreen()
+	e.Refresh()
+}
+
 // Reset resets the editor and starts over with an empty line.
 func (e *Ed) Reset() {
 	e.reset()
+	e.nav = nil
+	e.comp = nil
+	e.menu = nil
+	e.modal = nil
+	e.pager = nil
+	if e.job != nil {
+		e.job.cancel()
+		e.job = nil
+	}
+	e.undos = nil
+	e.redos = nil
+	if e.vi != nil {
+		e.vi.reset()
+	}
 	e.Refresh()
 }
 
@@ -329,7 +740,7 @@ func (e *Ed) SetCursor(pos ...int) {
 	if len(pos) > 0 {
 		e.Pos = pos[0]
 	}
-	e.term.SetCursor(e.Pos + len(e.Prompt))
+	e.term.SetCursor(e.Pos + len(e.prompt()))
 }
 
 // MoveCursor moves the cursor by the given number of chars in the given
@@ -370,6 +781,7 @@ func (e *Ed) reset() {
 	e.Pos = 0
 	e.Chars = []byte{}
 	e.Suggested = []byte{}
+	e.suggestedFor = nil
 	e.list = nil
 }
 
@@ -377,7 +789,10 @@ func (e *Ed) Refresh() {
 	e.update()
 	e.clearLine()
 	if len(e.Chars) > 0 {
-		e.Write(concat(e.Chars, e.Suggested))
+		e.Write(e.Chars)
+	}
+	if len(e.Suggested) > 0 {
+		e.Write(Colored(e.suggestionColor(), e.Suggested))
 	}
 	e.SetCursor()
 }
@@ -394,7 +809,11 @@ func (e *Ed) update() {
 
 func (e *Ed) clearLine() {
 	e.term.ClearLine()
-	e.Write(e.Prompt)
+	if e.PromptColor == Reset {
+		e.Write(e.prompt())
+	} else {
+		e.Write(Colored(e.PromptColor, e.prompt()))
+	}
 }
 
 func (e *Ed) clear() {
@@ -338,6 +338,266 @@ func TestDeleteFromCursorAtStart(t *testing.T) {
 	})
 }
 
+// DeleteToCursor
+
+func TestDeleteToCursorInMiddle(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar")
+	prompt.Left()
+	prompt.Left()
+	prompt.DeleteToCursor()
+
+	assert.Equal(t, "ar", prompt.Str())
+	assert.Equal(t, 0, prompt.Pos)
+}
+
+// Kill & Yank
+
+func TestKillYank(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar")
+	receive(term, key(CtrlW))
+	receive(term, key(CtrlA))
+	receive(term, key(CtrlY))
+
+	assert.Equal(t, "barfoo ", prompt.Str())
+	assert.Equal(t, 3, prompt.Pos)
+}
+
+func TestKillConsecutive(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar baz")
+	receive(term, key(CtrlW))
+	receive(term, key(CtrlW))
+	receive(term, key(CtrlY))
+
+	assert.Equal(t, "foo bar baz", prompt.Str())
+}
+
+func TestKillYankPop(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar")
+	receive(term, key(CtrlW))
+	receive(term, key(Left))
+	receive(term, key(CtrlK))
+	receive(term, key(CtrlY))
+	assert.Equal(t, "foo ", prompt.Str())
+
+	receive(term, key(AltY))
+	assert.Equal(t, "foobar", prompt.Str())
+	assert.Equal(t, 6, prompt.Pos)
+
+	receive(term, key(AltY))
+	assert.Equal(t, "foo ", prompt.Str())
+}
+
+func TestYankPopWithoutYank(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar")
+	receive(term, key(CtrlW))
+	receive(term, key(CtrlW))
+	receive(term, key(AltY))
+
+	assert.Equal(t, "", prompt.Str())
+}
+
+// Undo & Redo
+
+func TestUndoTyping(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "f")
+	receive(term, "o")
+	receive(term, "o")
+	receive(term, key(Left))
+	receive(term, "x")
+	assert.Equal(t, "foxo", prompt.Str())
+
+	receive(term, key(CtrlUnderscore))
+	assert.Equal(t, "foo", prompt.Str())
+	assert.Equal(t, 2, prompt.Pos)
+
+	receive(term, key(CtrlUnderscore))
+	assert.Equal(t, "", prompt.Str())
+}
+
+func TestUndoSeq(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar")
+	receive(term, key(CtrlU))
+	assert.Equal(t, "", prompt.Str())
+
+	receive(term, key(CtrlX))
+	receive(term, key(CtrlU))
+	assert.Equal(t, "foo bar", prompt.Str())
+	assert.Equal(t, 7, prompt.Pos)
+}
+
+func TestUndoSet(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo")
+	prompt.Set([]byte("bar"))
+	prompt.Undo()
+	assert.Equal(t, "foo", prompt.Str())
+}
+
+func TestRedo(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo")
+	receive(term, key(Backspace))
+	prompt.Undo()
+	assert.Equal(t, "foo", prompt.Str())
+
+	prompt.Redo()
+	assert.Equal(t, "fo", prompt.Str())
+	assert.Equal(t, 2, prompt.Pos)
+
+	prompt.Redo()
+	assert.Equal(t, "fo", prompt.Str())
+}
+
+// Words
+
+func TestForwardBackwardWord(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "cd /usr/local")
+	receive(term, key(AltB))
+	assert.Equal(t, 8, prompt.Pos)
+
+	receive(term, key(AltB))
+	assert.Equal(t, 4, prompt.Pos)
+
+	receive(term, key(AltF))
+	assert.Equal(t, 7, prompt.Pos)
+}
+
+func TestKillWord(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "cd /usr/local")
+	receive(term, key(CtrlA))
+	receive(term, key(AltF))
+	receive(term, key(AltD))
+	receive(term, key(AltD))
+	assert.Equal(t, "cd", prompt.Str())
+
+	receive(term, key(CtrlY))
+	assert.Equal(t, "cd /usr/local", prompt.Str())
+}
+
+func TestBackKillWord(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "cd /usr/local/")
+	receive(term, key(AltBackspace))
+	assert.Equal(t, "cd /usr/", prompt.Str())
+	assert.Equal(t, 8, prompt.Pos)
+}
+
+func TestCaseWords(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar baz")
+	receive(term, key(CtrlA))
+	receive(term, key(AltU))
+	receive(term, key(AltC))
+	assert.Equal(t, "FOO Bar baz", prompt.Str())
+	assert.Equal(t, 7, prompt.Pos)
+
+	receive(term, key(AltB))
+	receive(term, key(AltL))
+	assert.Equal(t, "FOO bar baz", prompt.Str())
+}
+
+func TestCaseWordsUnicode(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "über ıx")
+	receive(term, key(CtrlA))
+	receive(term, key(AltC))
+	assert.Equal(t, "Über ıx", prompt.Str())
+	assert.Equal(t, len("Über"), prompt.Pos)
+	receive(term, key(AltU))
+	assert.Equal(t, "Über IX", prompt.Str())
+	assert.Equal(t, len("Über IX"), prompt.Pos)
+}
+
+func TestTransposeWords(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar baz")
+	receive(term, key(AltT))
+	assert.Equal(t, "foo baz bar", prompt.Str())
+	assert.Equal(t, 11, prompt.Pos)
+
+	receive(term, key(CtrlA))
+	receive(term, key(AltF))
+	receive(term, key(AltT))
+	assert.Equal(t, "baz foo bar", prompt.Str())
+	assert.Equal(t, 7, prompt.Pos)
+}
+
+func TestClearScreen(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo")
+	receive(term, key(CtrlL))
+	assert.Equal(t, "foo", prompt.Str())
+	assertOut(t, term, []string{
+		"<cr><clear>t ~ <cr><rgt-4>",
+		"foo",
+		"<clear-screen><cr><clear>t ~ foo<cr><rgt-7>",
+	})
+}
+
+// Numeric arguments
+
+func TestArgBack(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar")
+	receive(term, "\x1b4")
+	assert.Equal(t, []byte("(arg: 4) "), prompt.prompt())
+
+	receive(term, key(Backspace))
+	assert.Equal(t, "foo", prompt.Str())
+	assert.Equal(t, 3, prompt.Pos)
+	assert.Equal(t, []byte("t ~ "), prompt.prompt())
+	assert.Equal(t, 1, prompt.Arg())
+}
+
+func TestArgMultipleDigits(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar baz")
+	receive(term, "\x1b1")
+	receive(term, "0")
+	receive(term, key(Left))
+	assert.Equal(t, 1, prompt.Pos)
+}
+
+func TestArgNegative(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar")
+	receive(term, key(CtrlA))
+	receive(term, "\x1b-")
+	receive(term, "2")
+	receive(term, key(Backspace))
+	assert.Equal(t, "o bar", prompt.Str())
+	assert.Equal(t, 0, prompt.Pos)
+}
+
+func TestArgBackWord(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar baz")
+	receive(term, "\x1b2")
+	receive(term, key(CtrlW))
+	assert.Equal(t, "foo ", prompt.Str())
+
+	receive(term, key(CtrlY))
+	assert.Equal(t, "foo bar baz", prompt.Str())
+}
+
+func TestUniversalArgument(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "foo bar baz")
+	prompt.Handle(CtrlT, func(e *Ed, k Key) { e.UniversalArgument() })
+	receive(term, key(CtrlT))
+	receive(term, key(CtrlB))
+	assert.Equal(t, 7, prompt.Pos)
+}
+
 // Transpose
 
 func TestTransposeAtStart(t *testing.T) {
@@ -415,11 +675,11 @@ func TestHistoryNextEmpty(t *testing.T) {
 	prompt, term := setup()
 	prompt.HistoryNext(h)
 
-	assert.Equal(t, "foo", prompt.Str())
-	assert.Equal(t, 3, prompt.Pos)
+	assert.Equal(t, "", prompt.Str())
+	assert.Equal(t, 0, prompt.Pos)
 	assertOut(t, term, []string{
 		"<cr><clear>t ~ <cr><rgt-4>",
-		"<cr><rgt-4><clear>foo",
+		"<bell>",
 	})
 }
 
@@ -440,6 +700,111 @@ func TestHistoryPrevEmpty(t *testing.T) {
 	})
 }
 
+func TestHistoryRestoresDraft(t *testing.T) {
+	h := [][]byte{
+		[]byte("foo"),
+		[]byte("bar"),
+	}
+	prompt, term := setup()
+	receive(term, "ba")
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "bar", prompt.Str())
+
+	prompt.HistoryNext(h)
+	assert.Equal(t, "ba", prompt.Str())
+	assert.Equal(t, 2, prompt.Pos)
+}
+
+func TestHistoryRemembersEdits(t *testing.T) {
+	h := [][]byte{
+		[]byte("foo"),
+		[]byte("bar"),
+		[]byte("baz"),
+	}
+	prompt, term := setup()
+	prompt.HistoryPrev(h)
+	prompt.HistoryPrev(h)
+	receive(term, "!")
+	assert.Equal(t, "bar!", prompt.Str())
+
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "foo", prompt.Str())
+
+	prompt.HistoryNext(h)
+	assert.Equal(t, "bar!", prompt.Str())
+
+	prompt.HistoryNext(h)
+	prompt.HistoryNext(h)
+	assert.Equal(t, "", prompt.Str())
+	assert.Equal(t, "bar", string(h[1]))
+
+	prompt.Reset()
+	prompt.HistoryPrev(h)
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "bar", prompt.Str())
+}
+
+func TestHistoryPrefix(t *testing.T) {
+	h := [][]byte{
+		[]byte("git commit -m foo"),
+		[]byte("git status"),
+		[]byte("git commit --amend"),
+		[]byte("git commit -m foo"),
+	}
+	prompt, term := setup()
+	prompt.HistoryMode = Prefix
+	receive(term, "git com")
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "git commit -m foo", prompt.Str())
+	assert.Equal(t, 7, prompt.Pos)
+
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "git commit --amend", prompt.Str())
+	assert.Equal(t, 7, prompt.Pos)
+
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "git commit --amend", prompt.Str())
+
+	prompt.HistoryNext(h)
+	prompt.HistoryNext(h)
+	assert.Equal(t, "git com", prompt.Str())
+	assert.Equal(t, 7, prompt.Pos)
+}
+
+func TestHistorySubstr(t *testing.T) {
+	h := [][]byte{
+		[]byte("git commit -m foo"),
+		[]byte("git status"),
+	}
+	prompt, term := setup()
+	prompt.HistoryMode = Substr
+	receive(term, "stat")
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "git status", prompt.Str())
+	assert.Equal(t, 8, prompt.Pos)
+}
+
+func TestHistoryFirstLast(t *testing.T) {
+	prompt, term := setup()
+	prompt.Hist.Add([]byte("foo"))
+	prompt.Hist.Add([]byte("bar"))
+	receive(term, "b")
+	receive(term, key(AltLt))
+	assert.Equal(t, "foo", prompt.Str())
+	receive(term, key(Down))
+	assert.Equal(t, "bar", prompt.Str())
+
+	receive(term, key(AltGt))
+	assert.Equal(t, "b", prompt.Str())
+
+	receive(term, key(Up))
+	assert.Equal(t, "bar", prompt.Str())
+	receive(term, key(AltLt))
+	assert.Equal(t, "foo", prompt.Str())
+	receive(term, key(AltGt))
+	assert.Equal(t, "b", prompt.Str())
+}
+
 // Complete
 
 func TestCompleteEmpty(t *testing.T) {
@@ -503,13 +868,13 @@ func TestHistoryAndCompletion(t *testing.T) {
 	prompt.CompleteNext(c)
 	assert.Equal(t, "bar", prompt.Str())
 
-	prompt.HistoryNext(h)
-	assert.Equal(t, "bar 1", prompt.Str())
-
-	prompt.HistoryNext(h)
+	prompt.HistoryPrev(h)
 	assert.Equal(t, "bar 2", prompt.Str())
 
-	prompt.HistoryNext(c)
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "bar 1", prompt.Str())
+
+	prompt.HistoryPrev(c)
 	assert.Equal(t, "bar", prompt.Str())
 }
 
@@ -548,6 +913,24 @@ func receive(t *testTerm, str string) {
 	time.Sleep(1 * time.Millisecond)
 }
 
+// await runs the given condition on the editor's event loop until it holds,
+// failing the test if it doesn't within a second.
+func await(t *testing.T, e *Ed, cond func(e *Ed) bool) {
+	timeout := time.After(time.Second)
+	for {
+		c := make(chan bool, 1)
+		e.Do(func(e *Ed) { c <- cond(e) })
+		select {
+		case ok := <-c:
+			if ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("condition not met")
+		}
+	}
+}
+
 func reset(term *testTerm) {
 	term.out = ""
 }
@@ -569,6 +952,8 @@ func newTestTerm() *testTerm {
 type testTerm struct {
 	keys chan string
 	out  string
+	cols int
+	rows int
 }
 
 func (t *testTerm) Start() {
@@ -595,6 +980,10 @@ func (t *testTerm) Close() error {
 	return nil
 }
 
+func (t *testTerm) Size() (int, int) {
+	return t.cols, t.rows
+}
+
 func (t *testTerm) RawMode() error {
 	return nil
 }
@@ -7,22 +7,32 @@ import (
 var blank = []byte{}
 
 // NewList returns a list of strings that are used for completion, history, and
-// suggestions.
+// suggestions. In Comp mode strings are matched by the last word of the given
+// string, in Hist mode by its first word. In Prefix and Substr mode the whole
+// string is used as a prefix or substring, and duplicate lines are skipped.
+// Strings are matched using the list's Matcher, if set.
 func NewList(strs [][]byte, str []byte, mode int) *List {
-	if mode == Comp {
-		str = lastWord(str)
-	} else {
-		str = firstWord(str)
+	switch mode {
+	case Comp:
+		str = []byte(lastToken(str).Word)
+	case Prefix, Substr:
+	default:
+		t := firstToken(str)
+		str = str[t.Start:t.End]
 	}
-	return &List{strs: strs, str: str, curr: -1}
+	return &List{strs: strs, str: str, mode: mode, curr: -1}
 }
 
 // List represents a list of strings that are used for completion, history, and
 // suggestions.
 type List struct {
-	curr int
-	strs [][]byte
-	str  []byte
+	// Matcher matches the strings, by default PrefixMatcher, or SubstrMatcher
+	// in Substr mode.
+	Matcher Matcher
+	curr    int
+	mode    int
+	strs    [][]byte
+	str     []byte
 }
 
 // Next returns the next string from the list.
@@ -65,14 +75,39 @@ func (c *List) eq(other *List) bool {
 }
 
 func (c *List) matches() [][]byte {
-	if len(c.str) == 0 {
-		return c.strs
+	strs := match(c.matcher(), c.strs, c.str)
+	if c.mode == Prefix || c.mode == Substr {
+		return uniq(strs)
+	}
+	return strs
+}
+
+func (c *List) matcher() Matcher {
+	switch {
+	case c.Matcher != nil:
+		return c.Matcher
+	case c.mode == Substr:
+		return SubstrMatcher
 	}
-	m := [][]byte{}
-	for _, s := range c.strs {
-		if bytes.HasPrefix(s, c.str) {
-			m = append(m, s)
+	return PrefixMatcher
+}
+
+// uniq removes duplicate strings, keeping the last occurrence.
+func uniq(strs [][]byte) [][]byte {
+	seen := map[string]bool{}
+	m := make([][]byte, len(strs))
+	i := len(strs)
+	for j := len(strs) - 1; j >= 0; j-- {
+		if !seen[string(strs[j])] {
+			seen[string(strs[j])] = true
+			i--
+			m[i] = strs[j]
 		}
 	}
-	return m
+	return m[i:]
+}
+
+// hasPrefixFold reports whether b begins with prefix, ignoring case.
+func hasPrefixFold(b []byte, prefix []byte) bool {
+	return len(b) >= len(prefix) && bytes.EqualFold(b[:len(prefix)], prefix)
 }
@@ -0,0 +1,110 @@
+package led
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// insertPrefix inserts the longest common prefix of all candidates, or rings
+// the bell if it does not add anything.
+func (e *Ed) insertPrefix() {
+	c := e.comp
+	prefix := c.cands[0].Text
+	for _, cand := range c.cands[1:] {
+		prefix = commonPrefix(prefix, cand.Text)
+	}
+	if len(prefix) <= c.end-c.start {
+		e.Bell()
+		return
+	}
+	e.insertCandidate(Candidate{Text: prefix})
+}
+
+// listCandidates lists all candidates below the line, asking for
+// confirmation first if there are at least CompletionQueryItems candidates.
+func (e *Ed) listCandidates() {
+	n := len(e.comp.cands)
+	if e.CompletionQueryItems > 0 && n >= e.CompletionQueryItems {
+		e.Write([]byte(fmt.Sprintf("\r\nDisplay all %d possibilities? (y or n)", n)))
+		e.modal = (*Ed).queryKey
+		return
+	}
+	e.showList()
+}
+
+// queryKey handles the answer to the confirmation for long listings.
+func (e *Ed) queryKey(k Key) bool {
+	switch {
+	case k.Str() == "y" || k.Str() == "Y" || k.Str() == " ":
+		e.modal = nil
+		e.showList()
+	case k.Str() == "n" || k.Str() == "N" || k.Code == Backspace || k.Code == CtrlC || k.Code == Esc:
+		e.modal = nil
+		e.Write([]byte("\r\n"))
+		e.Refresh()
+	default:
+		e.Bell()
+	}
+	return true
+}
+
+// showList shows the candidates in columns below the line, a page at a time
+// if they do not fit on the screen.
+func (e *Ed) showList() {
+	width, _ := e.term.Size()
+	e.pager, _ = menuLines(e.comp.cands, -1, width, math.MaxInt32)
+	e.Write([]byte("\r\n"))
+	e.page(e.pageSize())
+}
+
+// page shows the given number of lines from the pager, followed by a
+// `--More--` prompt if there are more lines, or the line being edited.
+func (e *Ed) page(n int) {
+	for ; n > 0 && len(e.pager) > 0; n-- {
+		e.Write([]byte(e.pager[0] + "\r\n"))
+		e.pager = e.pager[1:]
+	}
+	if len(e.pager) == 0 {
+		e.modal = nil
+		e.Refresh()
+		return
+	}
+	e.Write([]byte("--More--"))
+	e.modal = (*Ed).moreKey
+}
+
+// moreKey handles keys at the `--More--` prompt: Space and `y` show the next
+// page, Enter shows the next line, any other key stops the listing.
+func (e *Ed) moreKey(k Key) bool {
+	e.Write(concat(dup(Ansi(Cr)), Ansi(ClearLine)))
+	switch {
+	case k.Str() == " " || k.Str() == "y":
+		e.page(e.pageSize())
+	case k.Code == Enter:
+		e.page(1)
+	default:
+		e.pager = nil
+		e.modal = nil
+		e.Refresh()
+	}
+	return true
+}
+
+func (e *Ed) pageSize() int {
+	_, height := e.term.Size()
+	return max(1, height-1)
+}
+
+// commonPrefix returns the longest common prefix of the given strings,
+// without splitting multi-byte chars.
+func commonPrefix(a string, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	for i > 0 && i < len(a) && !utf8.RuneStart(a[i]) {
+		i--
+	}
+	return a[:i]
+}
@@ -0,0 +1,103 @@
+package led
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func numCompleter(n int) Completer {
+	return CompleterFunc(func(line []byte, pos int) ([]Candidate, int, int) {
+		cands := []Candidate{}
+		for i := 0; i < n; i++ {
+			cands = append(cands, Candidate{Text: fmt.Sprintf("file%02d", i), Suffix: " "})
+		}
+		return cands, 0, pos
+	})
+}
+
+func setupList(n int) (*Ed, *testTerm) {
+	prompt, term := setup()
+	term.cols, term.rows = 20, 4
+	prompt.Completer = numCompleter(n)
+	prompt.CompletionStyle = CompleteList
+	receive(term, "f")
+	return prompt, term
+}
+
+func TestListPrefix(t *testing.T) {
+	prompt, term := setupList(3)
+	receive(term, key(Tab))
+	assert.Equal(t, "file0", prompt.Str())
+
+	reset(term)
+	receive(term, key(Tab))
+	assert.Equal(t, "file0", prompt.Str())
+	assertOut(t, term, []string{
+		"<cr><nl>file00  file01<cr><nl>file02<cr><nl>",
+		"<cr><clear>t ~ file0<cr><rgt-9>",
+	})
+}
+
+func TestListSingle(t *testing.T) {
+	prompt, term := setupList(1)
+	receive(term, key(Tab))
+	assert.Equal(t, "file00 ", prompt.Str())
+}
+
+func TestListNoPrefix(t *testing.T) {
+	prompt, term := setupList(2)
+	receive(term, key(Tab))
+	reset(term)
+	receive(term, "1")
+	prompt.Completer = CompleterFunc(func(line []byte, pos int) ([]Candidate, int, int) {
+		return []Candidate{{Text: "file1"}, {Text: "file10"}}, 0, pos
+	})
+	receive(term, key(Tab))
+	assert.Equal(t, "file01", prompt.Str())
+	assert.Contains(t, term.out, string(Ansi(Bell)))
+}
+
+func TestListQuery(t *testing.T) {
+	prompt, term := setupList(3)
+	prompt.CompletionQueryItems = 3
+	receive(term, key(Tab))
+	reset(term)
+	receive(term, key(Tab))
+	assertOut(t, term, []string{"<cr><nl>Display all 3 possibilities? (y or n)"})
+
+	reset(term)
+	receive(term, "x")
+	receive(term, "n")
+	assertOut(t, term, []string{"<bell><cr><nl><cr><clear>t ~ file0<cr><rgt-9>"})
+	assert.Equal(t, "file0", prompt.Str())
+
+	receive(term, key(Tab))
+	receive(term, key(Tab))
+	receive(term, "y")
+	assert.Contains(t, term.out, "file02")
+}
+
+func TestListPager(t *testing.T) {
+	prompt, term := setupList(12)
+	receive(term, key(Tab))
+	reset(term)
+	receive(term, key(Tab))
+	assertOut(t, term, []string{"<cr><nl>file00  file01<cr><nl>file02  file03<cr><nl>file04  file05<cr><nl>--More--"})
+
+	reset(term)
+	receive(term, key(Enter))
+	assertOut(t, term, []string{"<cr><clear-line>file06  file07<cr><nl>--More--"})
+
+	reset(term)
+	receive(term, " ")
+	assertOut(t, term, []string{"<cr><clear-line>file08  file09<cr><nl>file10  file11<cr><nl><cr><clear>t ~ file<cr><rgt-8>"})
+
+	receive(term, key(Tab))
+	reset(term)
+	receive(term, key(Tab))
+	receive(term, "q")
+	assert.Nil(t, prompt.modal)
+	receive(term, "x")
+	assert.Equal(t, "filex", prompt.Str())
+}
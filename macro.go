@@ -0,0 +1,130 @@
+package led
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// macroPrefix prefixes macro names in key bindings, e.g. `macro:greet`.
+const macroPrefix = "macro:"
+
+// Macro represents a named sequence of keys.
+type Macro struct {
+	Name string
+	Keys []Key
+}
+
+// String returns the macro's name, and its keys as a quoted string, as used
+// in macro files.
+func (m *Macro) String() string {
+	b := []byte{}
+	for _, k := range m.Keys {
+		b = append(b, k.Chars...)
+	}
+	return m.Name + " " + strconv.Quote(string(b))
+}
+
+// ParseMacro parses a macro from a line in the format returned by
+// Macro.String, e.g. `greet "echo hello\r"`.
+func ParseMacro(line string) (*Macro, error) {
+	parts := strings.SplitN(strings.TrimSpace(line), " ", 2)
+	if len(parts) < 2 {
+		return nil, errors.New("invalid macro: " + line)
+	}
+	s, err := strconv.Unquote(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid macro %s: %v", parts[0], err)
+	}
+	return &Macro{Name: parts[0], Keys: ParseKeys([]byte(s))}, nil
+}
+
+// StartMacro starts recording keys into a new macro.
+func (e *Ed) StartMacro() {
+	e.macro = &Macro{}
+}
+
+// EndMacro stops recording keys. The recorded macro can be replayed with
+// PlayMacro, and named with NameMacro.
+func (e *Ed) EndMacro() {
+	if e.macro == nil {
+		return
+	}
+	e.lastMacro = e.macro
+	e.macro = nil
+}
+
+// NameMacro names the most recently recorded macro, so it can be replayed by
+// its name, and saved.
+func (e *Ed) NameMacro(name string) {
+	if e.lastMacro == nil {
+		return
+	}
+	e.lastMacro.Name = name
+	e.Macros[name] = e.lastMacro
+}
+
+// PlayMacro replays the macro with the given name, defaulting to the most
+// recently recorded macro. Its keys are run through the same handlers as
+// keys typed by the user. Honours the numeric argument (see Arg). Macros
+// can't be played while recording, or from within a macro.
+func (e *Ed) PlayMacro(name ...string) {
+	m := e.lastMacro
+	if len(name) > 0 {
+		m = e.Macros[name[0]]
+	}
+	if m == nil || e.macro != nil {
+		return
+	}
+	keys := []Key{}
+	for i := e.Arg(); i > 0; i-- {
+		keys = append(keys, m.Keys...)
+	}
+	e.play(keys)
+}
+
+// LoadMacros loads named macros from the given file, one macro per line in
+// the format returned by Macro.String. Empty lines and lines starting with
+// `#` are ignored.
+func (e *Ed) LoadMacros(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		m, err := ParseMacro(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		e.Macros[m.Name] = m
+	}
+	return s.Err()
+}
+
+// SaveMacros writes all named macros to the given file.
+func (e *Ed) SaveMacros(path string) error {
+	names := []string{}
+	for name := range e.Macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, name := range names {
+		b.WriteString(e.Macros[name].String() + "\n")
+	}
+	return ioutil.WriteFile(path, b.Bytes(), 0644)
+}
@@ -0,0 +1,117 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMacroRecordAndPlay(t *testing.T) {
+	prompt, term := setup()
+	receive(term, key(CtrlX))
+	receive(term, "(")
+	receive(term, "fo")
+	receive(term, key(Left))
+	receive(term, "x")
+	receive(term, key(CtrlE))
+	receive(term, key(CtrlX))
+	receive(term, ")")
+	assert.Equal(t, "fxo", prompt.Str())
+
+	receive(term, key(CtrlX))
+	receive(term, "e")
+	assert.Equal(t, "fxofxo", prompt.Str())
+	assert.Len(t, prompt.lastMacro.Keys, 4)
+}
+
+func TestMacroWithArg(t *testing.T) {
+	prompt, term := setup()
+	receive(term, "abcdefgh")
+	receive(term, key(CtrlX))
+	receive(term, "(")
+	receive(term, "\x1b2")
+	receive(term, key(Backspace))
+	receive(term, key(CtrlX))
+	receive(term, ")")
+	assert.Equal(t, "abcdef", prompt.Str())
+
+	receive(term, "\x1b2")
+	receive(term, key(CtrlX))
+	receive(term, "e")
+	assert.Equal(t, "ab", prompt.Str())
+}
+
+func TestMacroSaveLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "led")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "macros")
+
+	prompt, term := setup()
+	prompt.StartMacro()
+	receive(term, "ls")
+	receive(term, key(CtrlA))
+	prompt.EndMacro()
+	prompt.NameMacro("ls")
+	assert.NoError(t, prompt.SaveMacros(file))
+
+	data, _ := ioutil.ReadFile(file)
+	assert.Equal(t, "ls \"ls\\x01\"\n", string(data))
+
+	other, term := setup()
+	assert.NoError(t, other.LoadMacros(file))
+	other.PlayMacro("ls")
+	assert.Equal(t, "ls", other.Str())
+	assert.Equal(t, 0, other.Pos)
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := ParseKeys([]byte("a\x1b[A\x1bfü\x01"))
+	assert.Equal(t, []Key{
+		{Code: Chars, Chars: []byte("a")},
+		Keys[Up],
+		Keys[AltF],
+		{Code: Chars, Chars: []byte("ü")},
+		Keys[CtrlA],
+	}, keys)
+	assert.Equal(t, []byte("\x1b2"), ParseKeys([]byte("\x1b2"))[0].Chars)
+}
+
+func TestMacroPlayWhileRecording(t *testing.T) {
+	prompt, term := setup()
+	receive(term, key(CtrlX))
+	receive(term, "(")
+	receive(term, "a")
+	receive(term, key(CtrlX))
+	receive(term, "e")
+	receive(term, key(CtrlX))
+	receive(term, ")")
+	assert.Equal(t, "a", prompt.Str())
+	assert.Len(t, prompt.lastMacro.Keys, 1)
+
+	prompt.lastMacro.Keys = append(prompt.lastMacro.Keys, ParseKeys([]byte("\x18e"))...)
+	receive(term, key(CtrlX))
+	receive(term, "e")
+	assert.Equal(t, "aa", prompt.Str())
+}
+
+func TestMacroConfigBinding(t *testing.T) {
+	prompt, term := setup()
+	c, err := ParseConfig([]byte(`{ "keys": { "Ctrl-B": "macro:greet" } }`))
+	assert.NoError(t, err)
+	assert.NoError(t, prompt.Configure(c))
+	prompt.Macros["greet"] = &Macro{Name: "greet", Keys: ParseKeys([]byte("hi"))}
+	receive(term, key(CtrlB))
+	assert.Equal(t, "hi", prompt.Str())
+
+	_, err = ParseConfig([]byte(`{ "keys": { "Ctrl-B": "macro:" } }`))
+	assert.EqualError(t, err, "line 1: unknown action: macro:")
+}
+
+func TestInputrcMacroRecursion(t *testing.T) {
+	prompt, term := setup()
+	assert.NoError(t, prompt.bindLine(`"\C-b": "a\C-b"`))
+	receive(term, key(CtrlB))
+	assert.Equal(t, "a", prompt.Str())
+}
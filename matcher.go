@@ -0,0 +1,183 @@
+package led
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"unicode"
+)
+
+// Matcher matches strings against a pattern typed by the user. It returns
+// whether the given string matches the pattern, and a score. Lists order
+// matching strings by their score, highest first, keeping the original order
+// for equal scores.
+type Matcher interface {
+	Match(str []byte, pattern []byte) (score int, ok bool)
+}
+
+// MatcherFunc adapts a func to the Matcher interface.
+type MatcherFunc func(str []byte, pattern []byte) (int, bool)
+
+// Match calls the func.
+func (f MatcherFunc) Match(str []byte, pattern []byte) (int, bool) {
+	return f(str, pattern)
+}
+
+// Built-in matchers
+var (
+	// PrefixMatcher matches strings starting with the pattern.
+	PrefixMatcher Matcher = MatcherFunc(matchPrefix)
+	// IgnoreCaseMatcher matches strings starting with the pattern, ignoring
+	// case.
+	IgnoreCaseMatcher Matcher = MatcherFunc(matchPrefixFold)
+	// SmartCaseMatcher matches strings starting with the pattern, ignoring
+	// case unless the pattern contains upper case chars.
+	SmartCaseMatcher Matcher = MatcherFunc(matchSmartCase)
+	// SubstrMatcher matches strings containing the pattern.
+	SubstrMatcher Matcher = MatcherFunc(matchSubstr)
+	// FuzzyMatcher matches strings containing the chars of the pattern in
+	// the same order, e.g. `gcm` matches `git-commit-message`. Matches at
+	// the start of words, and consecutive chars score higher, so do shorter
+	// strings. Case is ignored unless the pattern contains upper case chars.
+	FuzzyMatcher Matcher = MatcherFunc(matchFuzzy)
+)
+
+// RegexpMatcher returns a matcher that uses the pattern as a regular
+// expression. Strings never match invalid patterns.
+func RegexpMatcher() Matcher {
+	var pattern string
+	var reg *regexp.Regexp
+	return MatcherFunc(func(str []byte, p []byte) (int, bool) {
+		if reg == nil || string(p) != pattern {
+			pattern = string(p)
+			reg, _ = regexp.Compile(pattern)
+		}
+		return 0, reg != nil && reg.Match(str)
+	})
+}
+
+// matchers maps the names used in config files to matchers.
+var matchers = map[string]func() Matcher{
+	"prefix":      func() Matcher { return PrefixMatcher },
+	"ignore-case": func() Matcher { return IgnoreCaseMatcher },
+	"smart-case":  func() Matcher { return SmartCaseMatcher },
+	"substring":   func() Matcher { return SubstrMatcher },
+	"fuzzy":       func() Matcher { return FuzzyMatcher },
+	"regexp":      RegexpMatcher,
+}
+
+// match returns the strings matching the given pattern, ordered by their
+// score.
+func match(m Matcher, strs [][]byte, pattern []byte) [][]byte {
+	type scored struct {
+		str   []byte
+		score int
+	}
+	matches := []scored{}
+	for _, s := range strs {
+		if score, ok := m.Match(s, pattern); ok {
+			matches = append(matches, scored{s, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	r := [][]byte{}
+	for _, m := range matches {
+		r = append(r, m.str)
+	}
+	return r
+}
+
+func matchPrefix(str []byte, pattern []byte) (int, bool) {
+	return 0, bytes.HasPrefix(str, pattern)
+}
+
+func matchPrefixFold(str []byte, pattern []byte) (int, bool) {
+	return 0, hasPrefixFold(str, pattern)
+}
+
+func matchSmartCase(str []byte, pattern []byte) (int, bool) {
+	if hasUpper(pattern) {
+		return matchPrefix(str, pattern)
+	}
+	return matchPrefixFold(str, pattern)
+}
+
+func matchSubstr(str []byte, pattern []byte) (int, bool) {
+	return 0, bytes.Contains(str, pattern)
+}
+
+// Scores used by the fuzzy matcher
+const (
+	fuzzyChar        = 16
+	fuzzyWordStart   = 32
+	fuzzyConsecutive = 32
+)
+
+// matchFuzzy finds the best scoring positions for the chars of the pattern
+// in the string. best[i] holds the best score for the pattern chars matched
+// so far, with the last one at position i.
+func matchFuzzy(b []byte, pattern []byte) (int, bool) {
+	if len(pattern) == 0 {
+		return 0, true
+	}
+	fold := !hasUpper(pattern)
+	eq := func(a, b rune) bool {
+		return a == b || fold && unicode.ToLower(a) == unicode.ToLower(b)
+	}
+	str, chars := []rune(string(b)), []rune(string(pattern))
+
+	none := -1 << 31
+	best := make([]int, len(str))
+	for i := range best {
+		best[i] = none
+		if eq(str[i], chars[0]) {
+			best[i] = fuzzyBonus(str, i)
+		}
+	}
+	for _, c := range chars[1:] {
+		next := make([]int, len(str))
+		prev := none
+		for i := range str {
+			next[i] = none
+			if i > 0 && eq(str[i], c) {
+				score := prev
+				if best[i-1] != none {
+					score = max(score, best[i-1]+fuzzyConsecutive)
+				}
+				if score != none {
+					next[i] = score + fuzzyBonus(str, i)
+				}
+			}
+			if i > 0 {
+				prev = max(prev, best[i-1])
+			}
+		}
+		best = next
+	}
+
+	score := none
+	for _, s := range best {
+		score = max(score, s)
+	}
+	if score == none {
+		return 0, false
+	}
+	return score - len(str), true
+}
+
+func fuzzyBonus(str []rune, i int) int {
+	if i == 0 || str[i-1] < 0x80 && !isWordChar(byte(str[i-1])) || unicode.IsUpper(str[i]) && unicode.IsLower(str[i-1]) {
+		return fuzzyChar + fuzzyWordStart
+	}
+	return fuzzyChar
+}
+
+func hasUpper(b []byte) bool {
+	for _, c := range string(b) {
+		if unicode.IsUpper(c) {
+			return true
+		}
+	}
+	return false
+}
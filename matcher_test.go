@@ -0,0 +1,79 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	words := bytesOf("Foo", "foobar", "barfoo")
+	assert.Equal(t, []string{"foobar"}, strs(match(PrefixMatcher, words, []byte("foo"))))
+	assert.Equal(t, []string{"Foo", "foobar"}, strs(match(IgnoreCaseMatcher, words, []byte("foo"))))
+	assert.Equal(t, []string{"Foo", "foobar"}, strs(match(SmartCaseMatcher, words, []byte("foo"))))
+	assert.Equal(t, []string{"Foo"}, strs(match(SmartCaseMatcher, words, []byte("Fo"))))
+	assert.Equal(t, []string{"foobar", "barfoo"}, strs(match(SubstrMatcher, words, []byte("foo"))))
+	assert.Equal(t, []string{"foobar", "barfoo"}, strs(match(RegexpMatcher(), words, []byte("^f|^b"))))
+	assert.Equal(t, []string{}, strs(match(RegexpMatcher(), words, []byte("("))))
+}
+
+func TestFuzzyMatcher(t *testing.T) {
+	words := bytesOf("git-cherry-pick", "go-complete-me", "git-commit-message", "gcm", "gtm")
+	assert.Equal(t, []string{"gcm", "go-complete-me", "git-commit-message"}, strs(match(FuzzyMatcher, words, []byte("gcm"))))
+	assert.Equal(t, []string{"git-commit-message"}, strs(match(FuzzyMatcher, words, []byte("gcmsg"))))
+	assert.Equal(t, []string{"FooBar"}, strs(match(FuzzyMatcher, bytesOf("FooBar", "foobar"), []byte("FB"))))
+}
+
+func TestFuzzyMatcherUnicode(t *testing.T) {
+	words := bytesOf("Ärger", "ãr", "Ñandú", "nandu")
+	assert.Equal(t, []string{"Ärger"}, strs(match(FuzzyMatcher, words, []byte("äg"))))
+	assert.Equal(t, []string{"Ñandú"}, strs(match(FuzzyMatcher, words, []byte("ñú"))))
+	assert.Equal(t, []string{}, strs(match(FuzzyMatcher, words, []byte("Ãr"))))
+}
+
+func TestHistoryMatcher(t *testing.T) {
+	h := bytesOf("git cm", "git commit -m message", "git checkout main")
+	prompt, term := setup()
+	prompt.HistoryMatcher = FuzzyMatcher
+	receive(term, "gcm")
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "git cm", prompt.Str())
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "git checkout main", prompt.Str())
+	prompt.HistoryPrev(h)
+	assert.Equal(t, "git commit -m message", prompt.Str())
+}
+
+func TestCompletionMatcher(t *testing.T) {
+	prompt, term := setup()
+	prompt.Completer = WordCompleter(bytesOf("git-cherry-pick", "git-commit-message"), FuzzyMatcher)
+	receive(term, "gcm")
+	receive(term, key(Tab))
+	assert.Equal(t, "git-commit-message ", prompt.Str())
+}
+
+func TestCompletionIgnoreCaseTab(t *testing.T) {
+	prompt, term := setup()
+	prompt.Completer = WordCompleter(bytesOf("Makefile", "main.go"))
+	prompt.CompletionIgnoreCase = true
+	receive(term, "maK")
+	receive(term, key(Tab))
+	assert.Equal(t, "Makefile ", prompt.Str())
+
+	dir := pathTree()
+	defer os.RemoveAll(dir)
+	prompt, term = setup()
+	prompt.Completer = &PathCompleter{Dir: dir}
+	prompt.CompletionMatcher = SubstrMatcher
+	receive(term, "rc")
+	receive(term, key(Tab))
+	await(t, prompt, func(e *Ed) bool { return e.Str() == "src/" })
+}
+
+func bytesOf(s ...string) [][]byte {
+	b := [][]byte{}
+	for _, str := range s {
+		b = append(b, []byte(str))
+	}
+	return b
+}
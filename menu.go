@@ -0,0 +1,132 @@
+package led
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// menu represents the completion menu shown below the line.
+type menu struct {
+	cols int
+}
+
+func (e *Ed) openMenu(dir int) {
+	e.menu = &menu{}
+	e.modal = (*Ed).menuKey
+	e.selectCandidate(step(dir))
+	e.renderMenu()
+}
+
+// menuKey handles the given key while the menu is open, and returns false if
+// the key should be handled as usual.
+func (e *Ed) menuKey(k Key) bool {
+	switch k.Code {
+	case Tab, Right:
+		e.selectCandidate(1)
+	case ShiftTab, Left:
+		e.selectCandidate(-1)
+	case Down:
+		e.selectCandidate(e.menu.cols)
+	case Up:
+		e.selectCandidate(-e.menu.cols)
+	case Enter:
+		e.closeMenu()
+		return true
+	case Esc:
+		e.closeMenu()
+		e.redraw(e.comp.line, e.comp.pos)
+		return true
+	default:
+		e.closeMenu()
+		return false
+	}
+	e.renderMenu()
+	return true
+}
+
+// closeMenu clears the menu from the screen.
+func (e *Ed) closeMenu() {
+	e.menu = nil
+	e.modal = nil
+	e.Write(concat(dup(Ansi(Cr)), Ansi(Newline), Ansi(ClearDown), CursorUp(1)))
+	e.SetCursor()
+}
+
+// renderMenu shows the menu below the line, and moves the cursor back to the
+// line.
+func (e *Ed) renderMenu() {
+	width, height := e.term.Size()
+	lines, cols := menuLines(e.comp.cands, e.comp.curr, width, height)
+	e.menu.cols = cols
+
+	b := []byte{}
+	for _, l := range lines {
+		b = concat(b, Ansi(Cr), Ansi(Newline), Ansi(ClearLine), []byte(l))
+	}
+	e.Write(concat(b, Ansi(ClearDown), CursorUp(len(lines))))
+	e.SetCursor()
+}
+
+// menuLines lays out the given candidates in columns that fit the given
+// terminal width, highlighting the selected candidate. Candidates with
+// descriptions are shown one per line. If there are more lines than fit on
+// the screen below the line, the lines around the selected candidate are
+// returned. Returns the lines, and the number of columns.
+func menuLines(cands []Candidate, curr int, width int, height int) ([]string, int) {
+	items := []string{}
+	size := 0
+	descs := false
+	for _, c := range cands {
+		items = append(items, c.Str())
+		size = max(size, utf8.RuneCountInString(c.Str()))
+		descs = descs || c.Description != ""
+	}
+
+	cols := 1
+	if descs {
+		for i, c := range cands {
+			if c.Description != "" {
+				items[i] = pad(items[i], size) + "  -- " + c.Description
+			}
+		}
+	} else {
+		cols = max(1, (width+2)/(size+2))
+	}
+
+	rows := (len(items) + cols - 1) / cols
+	first, last := 0, rows
+	if height := max(1, height-2); rows > height {
+		first = max(0, curr/cols-height+1)
+		last = first + height
+	}
+
+	lines := []string{}
+	for r := first; r < last; r++ {
+		line := ""
+		for c := 0; c < cols && r*cols+c < len(items); c++ {
+			i := r*cols + c
+			item := truncate(items[i], width-1)
+			if i == curr {
+				line += string(Colored(Reverse, []byte(item)))
+			} else {
+				line += item
+			}
+			if c < cols-1 && i+1 < len(items) {
+				line += strings.Repeat(" ", size+2-utf8.RuneCountInString(item))
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, cols
+}
+
+func pad(s string, n int) string {
+	return s + strings.Repeat(" ", max(0, n-utf8.RuneCountInString(s)))
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:max(0, n)])
+}
@@ -0,0 +1,89 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var menuCompleter = CompleterFunc(func(line []byte, pos int) ([]Candidate, int, int) {
+	cands := []Candidate{}
+	for _, s := range []string{"git", "go", "grep", "gzip"} {
+		cands = append(cands, Candidate{Text: s, Suffix: " "})
+	}
+	return cands, 0, pos
+})
+
+func setupMenu() (*Ed, *testTerm) {
+	prompt, term := setup()
+	term.cols, term.rows = 20, 10
+	prompt.Completer = menuCompleter
+	prompt.CompletionStyle = CompleteMenu
+	receive(term, "g")
+	reset(term)
+	return prompt, term
+}
+
+func TestMenu(t *testing.T) {
+	prompt, term := setupMenu()
+	receive(term, key(Tab))
+	assert.Equal(t, "git ", prompt.Str())
+	assertOut(t, term, []string{
+		"<cr><rgt-4><clear>git <cr><rgt-8>",
+		"<cr><nl><clear-line><reverse>git<reset>   go    grep",
+		"<cr><nl><clear-line>gzip",
+		"<clear-down><up-2><cr><rgt-8>",
+	})
+
+	receive(term, key(Down))
+	assert.Equal(t, "gzip ", prompt.Str())
+	receive(term, key(Tab))
+	assert.Equal(t, "git ", prompt.Str())
+	receive(term, key(ShiftTab))
+	receive(term, key(Left))
+	assert.Equal(t, "grep ", prompt.Str())
+
+	reset(term)
+	receive(term, key(Enter))
+	assert.Equal(t, "grep ", prompt.Str())
+	assert.Nil(t, prompt.menu)
+	assertOut(t, term, []string{"<cr><nl><clear-down><up-1><cr><rgt-9>"})
+
+	receive(term, "x")
+	assert.Equal(t, "grep x", prompt.Str())
+}
+
+func TestMenuEsc(t *testing.T) {
+	prompt, term := setupMenu()
+	receive(term, key(Tab))
+	receive(term, key(Tab))
+	assert.Equal(t, "go ", prompt.Str())
+	receive(term, key(Esc))
+	assert.Equal(t, "g", prompt.Str())
+	assert.Equal(t, 1, prompt.Pos)
+}
+
+func TestMenuOtherKey(t *testing.T) {
+	prompt, term := setupMenu()
+	receive(term, key(Tab))
+	receive(term, "x")
+	assert.Nil(t, prompt.menu)
+	assert.Equal(t, "git x", prompt.Str())
+	prompt.Undo()
+	prompt.Undo()
+	assert.Equal(t, "g", prompt.Str())
+}
+
+func TestMenuLines(t *testing.T) {
+	cands := []Candidate{{Text: "a"}, {Text: "bb"}, {Text: "ccc"}, {Text: "dddd"}, {Text: "e"}}
+	lines, cols := menuLines(cands, -1, 12, 10)
+	assert.Equal(t, 2, cols)
+	assert.Equal(t, []string{"a     bb", "ccc   dddd", "e"}, lines)
+
+	lines, _ = menuLines(cands, 4, 12, 4)
+	assert.Equal(t, []string{"ccc   dddd", string(Colored(Reverse, []byte("e")))}, lines)
+
+	cands = []Candidate{{Text: "src", Description: "sources"}, {Text: "docs", Display: "docs/"}}
+	lines, cols = menuLines(cands, -1, 80, 10)
+	assert.Equal(t, 1, cols)
+	assert.Equal(t, []string{"src    -- sources", "docs/"}, lines)
+}
@@ -0,0 +1,161 @@
+package led
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// PathCompleter completes file system paths in the word left of the cursor.
+// It completes relative and absolute paths, expands `~` and `~user`, and
+// appends a slash to directories, and a space to files. Names containing
+// spaces or other special chars are escaped with backslashes, or quoted if
+// the word starts with a quote.
+type PathCompleter struct {
+	// Dir is the directory relative paths are resolved against, by default
+	// the working directory.
+	Dir string
+	// HideDotfiles skips files starting with a dot, unless the word being
+	// completed starts with one.
+	HideDotfiles bool
+	// DirsOnly skips anything but directories.
+	DirsOnly bool
+	// Extensions limits files to the given extensions, e.g. `.go`.
+	// Directories are always completed.
+	Extensions []string
+	// Filter, if set, skips files it returns false for, e.g. to complete
+	// executables only. Directories are always completed.
+	Filter func(path string, info os.FileInfo) bool
+	// Matcher matches file names, by default the editor's CompletionMatcher,
+	// or PrefixMatcher (see MatchingCompleter).
+	Matcher Matcher
+}
+
+// Complete implements the Completer interface.
+func (c *PathCompleter) Complete(line []byte, pos int) ([]Candidate, int, int) {
+	return c.CompleteContext(context.Background(), line, pos)
+}
+
+// CompleteContext implements the ContextCompleter interface. It stops
+// reading the directory once the context is done.
+func (c *PathCompleter) CompleteContext(ctx context.Context, line []byte, pos int) ([]Candidate, int, int) {
+	t := tokenAt(line, pos)
+	start, end, word, quote := t.Start, t.End, t.Word, t.Quote
+	if strings.HasPrefix(word, "~") && !strings.Contains(word, "/") {
+		if _, ok := homeDir(word[1:]); ok {
+			return []Candidate{{Text: quotePath(word, quote), Display: word + "/", Suffix: "/"}}, start, end
+		}
+		return nil, start, end
+	}
+
+	i := strings.LastIndexByte(word, '/') + 1
+	dir, base := word[:i], word[i:]
+	root := c.resolve(dir)
+	infos, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, start, end
+	}
+
+	names := [][]byte{}
+	files := map[string]bool{}
+	for _, info := range infos {
+		if ctx.Err() != nil {
+			return nil, start, end
+		}
+		name := info.Name()
+		path := filepath.Join(root, name)
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(path); err == nil {
+				info = target
+			}
+		}
+		if c.HideDotfiles && strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		if !info.IsDir() && !c.include(path, info) {
+			continue
+		}
+		names = append(names, []byte(name))
+		files[name] = !info.IsDir()
+	}
+
+	m := c.Matcher
+	if m == nil {
+		m = PrefixMatcher
+	}
+	cands := []Candidate{}
+	for _, name := range match(m, names, []byte(base)) {
+		cand := Candidate{Text: quotePath(dir+string(name), quote), Display: string(name) + "/", Suffix: "/"}
+		if files[string(name)] {
+			cand.Display = string(name)
+			cand.Suffix = closeQuote(quote)
+		}
+		cands = append(cands, cand)
+	}
+	return cands, start, end
+}
+
+// WithMatcher implements the MatchingCompleter interface.
+func (c *PathCompleter) WithMatcher(m Matcher) Completer {
+	if c.Matcher != nil {
+		return c
+	}
+	d := *c
+	d.Matcher = m
+	return &d
+}
+
+// include returns whether the given file is to be completed.
+func (c *PathCompleter) include(path string, info os.FileInfo) bool {
+	if c.DirsOnly {
+		return false
+	}
+	if len(c.Extensions) > 0 && indexOfStr(c.Extensions, filepath.Ext(path)) == -1 {
+		return false
+	}
+	return c.Filter == nil || c.Filter(path, info)
+}
+
+// resolve returns the directory to read for the given directory part of a
+// word.
+func (c *PathCompleter) resolve(dir string) string {
+	dir = expandHome(dir)
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Join(c.Dir, dir)
+}
+
+// quotePath quotes the given path like quoteWord, keeping a leading `~` or
+// `~user` as is.
+func quotePath(path string, quote byte) string {
+	home := ""
+	if strings.HasPrefix(path, "~") {
+		i := strings.IndexByte(path, '/')
+		if i == -1 {
+			i = len(path)
+		}
+		home, path = path[:i], path[i:]
+	}
+	return home + quoteWord(path, quote)
+}
+
+// homeDir returns the home directory of the user with the given name, or of
+// the current user if the name is empty.
+func homeDir(name string) (string, bool) {
+	if name == "" {
+		home, err := os.UserHomeDir()
+		return home, err == nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return "", false
+	}
+	return u.HomeDir, true
+}
@@ -0,0 +1,107 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func pathTree() string {
+	dir, _ := ioutil.TempDir("", "led")
+	os.Mkdir(filepath.Join(dir, "src"), 0755)
+	for _, name := range []string{"foo bar.txt", "foo.go", ".hidden", "src/main.go"} {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte{}, 0644)
+	}
+	return dir
+}
+
+func texts(cands []Candidate) []string {
+	s := []string{}
+	for _, c := range cands {
+		s = append(s, c.Text+c.Suffix)
+	}
+	return s
+}
+
+func TestPathCompleter(t *testing.T) {
+	dir := pathTree()
+	defer os.RemoveAll(dir)
+	c := &PathCompleter{Dir: dir}
+
+	cands, start, end := c.Complete([]byte("cat fo"), 6)
+	assert.Equal(t, []string{`foo\ bar.txt `, "foo.go "}, texts(cands))
+	assert.Equal(t, 4, start)
+	assert.Equal(t, 6, end)
+
+	cands, _, _ = c.Complete([]byte("cd s"), 4)
+	assert.Equal(t, []string{"src/"}, texts(cands))
+	assert.Equal(t, "src/", cands[0].Display)
+
+	cands, _, _ = c.Complete([]byte("cat src/"), 8)
+	assert.Equal(t, []string{"src/main.go "}, texts(cands))
+
+	cands, _, _ = c.Complete([]byte("cat "+dir+"/foo."), len(dir)+9)
+	assert.Equal(t, []string{dir + "/foo.go "}, texts(cands))
+}
+
+func TestPathCompleterQuoting(t *testing.T) {
+	dir := pathTree()
+	defer os.RemoveAll(dir)
+	c := &PathCompleter{Dir: dir}
+
+	cands, start, _ := c.Complete([]byte(`cat "foo `), 9)
+	assert.Equal(t, []string{`"foo bar.txt" `}, texts(cands))
+	assert.Equal(t, 4, start)
+
+	cands, _, _ = c.Complete([]byte(`cat foo\ b`), 10)
+	assert.Equal(t, []string{`foo\ bar.txt `}, texts(cands))
+
+	cands, _, _ = c.Complete([]byte(`cat 'foo b`), 10)
+	assert.Equal(t, []string{`'foo bar.txt' `}, texts(cands))
+}
+
+func TestPathCompleterHome(t *testing.T) {
+	dir := pathTree()
+	defer os.RemoveAll(dir)
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", dir)
+	c := &PathCompleter{}
+
+	cands, _, _ := c.Complete([]byte("cd ~/s"), 6)
+	assert.Equal(t, []string{"~/src/"}, texts(cands))
+
+	cands, _, _ = c.Complete([]byte("cd ~"), 4)
+	assert.Equal(t, []string{"~/"}, texts(cands))
+}
+
+func TestPathCompleterFilters(t *testing.T) {
+	dir := pathTree()
+	defer os.RemoveAll(dir)
+
+	c := &PathCompleter{Dir: dir, HideDotfiles: true}
+	cands, _, _ := c.Complete([]byte("cat "), 4)
+	assert.Equal(t, []string{`foo\ bar.txt `, "foo.go ", "src/"}, texts(cands))
+	cands, _, _ = c.Complete([]byte("cat ."), 5)
+	assert.Equal(t, []string{".hidden "}, texts(cands))
+
+	c = &PathCompleter{Dir: dir, Extensions: []string{".go"}}
+	cands, _, _ = c.Complete([]byte("cat "), 4)
+	assert.Equal(t, []string{"foo.go ", "src/"}, texts(cands))
+
+	c = &PathCompleter{Dir: dir, DirsOnly: true}
+	cands, _, _ = c.Complete([]byte("cd "), 3)
+	assert.Equal(t, []string{"src/"}, texts(cands))
+}
+
+func TestPathCompleterTab(t *testing.T) {
+	dir := pathTree()
+	defer os.RemoveAll(dir)
+	prompt, term := setup()
+	prompt.Completer = &PathCompleter{Dir: dir}
+	receive(term, "vim src/m")
+	receive(term, key(Tab))
+	await(t, prompt, func(e *Ed) bool { return e.Str() == "vim src/main.go " })
+}
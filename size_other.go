@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package led
+
+// Size returns zeros, as the terminal size can not be determined on this
+// platform.
+func (t *termWrap) Size() (int, int) {
+	return 0, 0
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package led
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Size returns the number of columns and rows of the terminal, or zeros if
+// they can not be determined.
+func (t *termWrap) Size() (int, int) {
+	var ws struct{ rows, cols, x, y uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0, 0
+	}
+	return int(ws.cols), int(ws.rows)
+}
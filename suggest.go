@@ -0,0 +1,232 @@
+package led
+
+import (
+	"bytes"
+	"context"
+)
+
+// Suggester returns the suggested rest of the given line, with the cursor at
+// the given position, or an empty slice if it has no suggestion.
+type Suggester interface {
+	Suggest(line []byte, pos int) []byte
+}
+
+// SuggesterFunc adapts a func to the Suggester interface.
+type SuggesterFunc func(line []byte, pos int) []byte
+
+// Suggest calls the func.
+func (f SuggesterFunc) Suggest(line []byte, pos int) []byte {
+	return f(line, pos)
+}
+
+// ContextSuggester is implemented by suggesters that may take a while. The
+// editor runs SuggestContext asynchronously (see Async), passing a context
+// that is cancelled if the user keeps typing, or the AsyncTimeout passes.
+// Suggesters wrapping other suggesters should forward it.
+type ContextSuggester interface {
+	Suggester
+	SuggestContext(ctx context.Context, line []byte, pos int) []byte
+}
+
+// AsyncSuggester adapts a func, that may take a while, to the
+// ContextSuggester interface.
+type AsyncSuggester func(ctx context.Context, line []byte, pos int) []byte
+
+// Suggest calls the func synchronously.
+func (f AsyncSuggester) Suggest(line []byte, pos int) []byte {
+	return f(context.Background(), line, pos)
+}
+
+// SuggestContext calls the func.
+func (f AsyncSuggester) SuggestContext(ctx context.Context, line []byte, pos int) []byte {
+	return f(ctx, line, pos)
+}
+
+// HistorySuggester returns a suggester that suggests the rest of the most
+// recent line in the given history matching the line, see ListSuggester.
+func HistorySuggester(h *History, m ...Matcher) Suggester {
+	return SuggesterFunc(func(line []byte, pos int) []byte {
+		lines := h.Lines()
+		strs := make([][]byte, len(lines))
+		for i, l := range lines {
+			strs[len(lines)-1-i] = l
+		}
+		return ListSuggester(strs, m...).Suggest(line, pos)
+	})
+}
+
+// ListSuggester returns a suggester that suggests the rest of the first of
+// the given strings matching the line using the given matcher, by default
+// PrefixMatcher. Strings need to start with the line, at least ignoring case,
+// strings spanning multiple lines are skipped.
+func ListSuggester(strs [][]byte, m ...Matcher) Suggester {
+	return SuggesterFunc(func(line []byte, pos int) []byte {
+		matcher := PrefixMatcher
+		if len(m) > 0 && m[0] != nil {
+			matcher = m[0]
+		}
+		for _, s := range strs {
+			if len(s) <= len(line) || !hasPrefixFold(s, line) || bytes.IndexByte(s, '\n') != -1 {
+				continue
+			}
+			if _, ok := matcher.Match(s, line); ok {
+				return dup(s[len(line):])
+			}
+		}
+		return []byte{}
+	})
+}
+
+// CompletionSuggester returns a suggester that suggests the rest of the first
+// candidate returned by the given completer for the word at the end of the
+// line, if any, e.g. to suggest commands from a spec. If the completer is a
+// ContextCompleter, so is the suggester.
+func CompletionSuggester(c Completer) Suggester {
+	f := func(ctx context.Context, line []byte, pos int) []byte {
+		var cands []Candidate
+		var start, end int
+		if cc, ok := c.(ContextCompleter); ok {
+			cands, start, end = cc.CompleteContext(ctx, line, pos)
+		} else {
+			cands, start, end = c.Complete(line, pos)
+		}
+		if end != pos || start == pos {
+			return []byte{}
+		}
+		word := string(line[start:pos])
+		for _, cand := range cands {
+			if len(cand.Text) > len(word) && cand.Text[:len(word)] == word {
+				return []byte(cand.Text[len(word):])
+			}
+		}
+		return []byte{}
+	}
+	if _, ok := c.(ContextCompleter); ok {
+		return AsyncSuggester(f)
+	}
+	return SuggesterFunc(func(line []byte, pos int) []byte {
+		return f(context.Background(), line, pos)
+	})
+}
+
+// ChainSuggester returns a suggester that asks the given suggesters in turn,
+// and returns the first suggestion. The order of the arguments is their
+// priority, e.g. a history suggestion wins over a completion suggestion if
+// the HistorySuggester is passed first. If any of the suggesters is a
+// ContextSuggester, so is the chain, passing the context on.
+func ChainSuggester(s ...Suggester) Suggester {
+	f := func(ctx context.Context, line []byte, pos int) []byte {
+		for _, suggester := range s {
+			if ctx.Err() != nil {
+				break
+			}
+			var r []byte
+			if cs, ok := suggester.(ContextSuggester); ok {
+				r = cs.SuggestContext(ctx, line, pos)
+			} else {
+				r = suggester.Suggest(line, pos)
+			}
+			if len(r) > 0 {
+				return r
+			}
+		}
+		return []byte{}
+	}
+	for _, suggester := range s {
+		if _, ok := suggester.(ContextSuggester); ok {
+			return AsyncSuggester(f)
+		}
+	}
+	return SuggesterFunc(func(line []byte, pos int) []byte {
+		return f(context.Background(), line, pos)
+	})
+}
+
+// AcceptSuggestion appends the suggested chars to the line, if the cursor is
+// at the end of the line, and returns whether there was a suggestion.
+func (e *Ed) AcceptSuggestion() bool {
+	return e.acceptSuggestion(len(e.Suggested))
+}
+
+// AcceptSuggestionWord appends the next word of the suggested chars to the
+// line, like AcceptSuggestion.
+func (e *Ed) AcceptSuggestionWord() bool {
+	line := concat(dup(e.Chars), e.Suggested)
+	return e.acceptSuggestion(forwardWord(line, e.Pos) - e.Pos)
+}
+
+func (e *Ed) acceptSuggestion(n int) bool {
+	if len(e.Suggested) == 0 || e.Pos != len(e.Chars) {
+		return false
+	}
+	s := e.Suggested
+	e.Suggested = []byte{}
+	e.Insert(s[:n])
+	e.Suggested = dup(s[n:])
+	e.indicate(e.AutoSuggestColor, string(e.Suggested))
+	return true
+}
+
+// autoSuggest asks the Suggester for a suggestion, and shows it after the
+// cursor, if a Suggester is given, or AutoSuggest is set. By default the rest
+// of the most recent history line matching the current line using the
+// SuggestMatcher is suggested, see HistorySuggester. Suggestions are only
+// shown with the cursor at the end of the line, and updated when the line
+// changes.
+func (e *Ed) autoSuggest() {
+	atEnd := len(e.Chars) > 0 && e.Pos == len(e.Chars)
+	if !e.suggesting() || atEnd && bytes.Equal(e.suggestedFor, e.Chars) {
+		return
+	}
+	e.suggestedFor = nil
+	if !atEnd {
+		e.showSuggestion([]byte{})
+		return
+	}
+
+	e.suggestedFor = dup(e.Chars)
+	line, pos := dup(e.Chars), e.Pos
+	s := e.Suggester
+	if s == nil {
+		s = HistorySuggester(e.Hist, e.SuggestMatcher)
+	}
+	if cs, ok := s.(ContextSuggester); ok {
+		e.showSuggestion([]byte{})
+		e.Async(func(ctx context.Context) func(*Ed) {
+			r := cs.SuggestContext(ctx, line, pos)
+			return func(e *Ed) { e.showSuggestion(r) }
+		})
+		return
+	}
+	e.showSuggestion(s.Suggest(line, pos))
+}
+
+// showSuggestion shows the given suggestion, replacing the current one.
+func (e *Ed) showSuggestion(s []byte) {
+	if len(s) == 0 && len(e.Suggested) == 0 {
+		return
+	}
+	e.Suggested = append([]byte{}, s...)
+	e.indicate(e.AutoSuggestColor, string(s))
+}
+
+// suggestionColor returns the color of the current suggestion: the
+// AutoSuggestColor if suggesting while typing, and the SuggestColor otherwise.
+func (e *Ed) suggestionColor() int {
+	if e.AutoSuggest {
+		return e.AutoSuggestColor
+	}
+	return e.SuggestColor
+}
+
+// hideSuggestion removes the suggestion from the screen before the line is
+// accepted.
+func (e *Ed) hideSuggestion() {
+	if e.suggesting() {
+		e.showSuggestion([]byte{})
+	}
+}
+
+func (e *Ed) suggesting() bool {
+	return e.AutoSuggest || e.Suggester != nil
+}
@@ -0,0 +1,169 @@
+package led
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func setupSuggest(lines ...string) (*Ed, *testTerm) {
+	prompt, term := setup()
+	prompt.AutoSuggest = true
+	for _, l := range lines {
+		prompt.Hist.Add([]byte(l))
+	}
+	return prompt, term
+}
+
+func TestAutoSuggest(t *testing.T) {
+	prompt, term := setupSuggest("git status", "git commit", "ls")
+	receive(term, "git ")
+	assert.Equal(t, "commit", string(prompt.Suggested))
+	assert.Contains(t, string(Deansi([]byte(term.out))), "<clear><dim>commit<reset>")
+
+	receive(term, "s")
+	assert.Equal(t, "tatus", string(prompt.Suggested))
+	receive(term, "x")
+	assert.Equal(t, "", string(prompt.Suggested))
+	receive(term, key(Backspace))
+	assert.Equal(t, "tatus", string(prompt.Suggested))
+
+	receive(term, key(Right))
+	assert.Equal(t, "git status", prompt.Str())
+	assert.Equal(t, 10, prompt.Pos)
+	assert.Equal(t, "", string(prompt.Suggested))
+}
+
+func TestAutoSuggestColor(t *testing.T) {
+	prompt, term := setupSuggest("git status")
+	prompt.AutoSuggestColor = Cyan
+	receive(term, "git")
+	assert.Contains(t, string(Deansi([]byte(term.out))), "<clear><cyan> status<reset>")
+	assert.Equal(t, Green, prompt.SuggestColor)
+
+	reset(term)
+	prompt.Refresh()
+	assert.Contains(t, string(Deansi([]byte(term.out))), "<cyan> status<reset>")
+}
+
+func TestAutoSuggestAtCursor(t *testing.T) {
+	prompt, term := setupSuggest("git status")
+	receive(term, "git")
+	receive(term, key(Left))
+	assert.Equal(t, "", string(prompt.Suggested))
+	receive(term, key(CtrlE))
+	assert.Equal(t, " status", string(prompt.Suggested))
+	assert.Equal(t, 3, prompt.Pos)
+	receive(term, key(CtrlE))
+	assert.Equal(t, "git status", prompt.Str())
+}
+
+func TestAutoSuggestWord(t *testing.T) {
+	prompt, term := setupSuggest("git commit -m foo")
+	receive(term, "git")
+	receive(term, key(AltF))
+	assert.Equal(t, "git commit", prompt.Str())
+	assert.Equal(t, " -m foo", string(prompt.Suggested))
+	receive(term, key(CtrlF))
+	assert.Equal(t, "git commit -m foo", prompt.Str())
+}
+
+func TestAutoSuggestIgnoreCase(t *testing.T) {
+	prompt, term := setupSuggest("Git status", "git\nstatus")
+	receive(term, "git")
+	assert.Equal(t, "", string(prompt.Suggested))
+	prompt.SuggestMatcher = IgnoreCaseMatcher
+	receive(term, " ")
+	receive(term, key(Backspace))
+	assert.Equal(t, " status", string(prompt.Suggested))
+}
+
+func TestAutoSuggestEnter(t *testing.T) {
+	prompt, term := setupSuggest("git status")
+	receive(term, "git")
+	reset(term)
+	receive(term, key(Enter))
+	assert.Equal(t, "", string(prompt.Suggested))
+	assertOut(t, term, []string{"<cr><rgt-7><clear><cr><rgt-7><nl>"})
+}
+
+func TestAutoSuggestAcceptLine(t *testing.T) {
+	prompt, term := setupSuggest("git status")
+	prompt.HandleSeqAction([]byte("\n"), "accept-line")
+	receive(term, "git")
+	assert.Equal(t, " status", string(prompt.Suggested))
+	receive(term, "\n")
+	assert.Equal(t, "", string(prompt.Suggested))
+
+	prompt, term = setupSuggest("git status")
+	prompt.Handle(Enter, func(e *Ed, k Key) {})
+	receive(term, "git")
+	receive(term, key(Enter))
+	assert.Equal(t, "", string(prompt.Suggested))
+}
+
+func TestSuggester(t *testing.T) {
+	prompt, term := setup()
+	prompt.Suggester = SuggesterFunc(func(line []byte, pos int) []byte {
+		if string(line) == "he" {
+			return []byte("llo")
+		}
+		return []byte{}
+	})
+	receive(term, "he")
+	assert.Equal(t, "llo", string(prompt.Suggested))
+	receive(term, key(CtrlE))
+	assert.Equal(t, "hello", prompt.Str())
+}
+
+func TestChainSuggester(t *testing.T) {
+	h := NewHistory()
+	h.Add([]byte("git status"))
+	s := ChainSuggester(
+		CompletionSuggester(WordCompleter(bytesOf("git-commit-message"))),
+		HistorySuggester(h),
+		ListSuggester(bytesOf("go build", "go test")),
+	)
+	assert.Equal(t, "-commit-message", string(s.Suggest([]byte("git"), 3)))
+	assert.Equal(t, "status", string(s.Suggest([]byte("git "), 4)))
+	assert.Equal(t, " build", string(s.Suggest([]byte("go"), 2)))
+	assert.Equal(t, "", string(s.Suggest([]byte("ls"), 2)))
+}
+
+func TestAsyncSuggester(t *testing.T) {
+	release := make(chan bool)
+	prompt, term := setup()
+	prompt.Suggester = AsyncSuggester(func(ctx context.Context, line []byte, pos int) []byte {
+		<-release
+		return []byte("llo")
+	})
+	receive(term, "he")
+	assert.Equal(t, "", string(prompt.Suggested))
+	close(release)
+	await(t, prompt, func(e *Ed) bool { return string(e.Suggested) == "llo" })
+}
+
+func TestChainSuggesterAsync(t *testing.T) {
+	cancelled := make(chan bool, 1)
+	prompt, term := setup()
+	prompt.Suggester = ChainSuggester(
+		ListSuggester(bytesOf("git status")),
+		AsyncSuggester(func(ctx context.Context, line []byte, pos int) []byte {
+			<-ctx.Done()
+			select {
+			case cancelled <- true:
+			default:
+			}
+			return []byte("llo")
+		}),
+	)
+	_, ok := prompt.Suggester.(ContextSuggester)
+	assert.True(t, ok)
+
+	receive(term, "he")
+	receive(term, "l")
+	assert.True(t, <-cancelled)
+	receive(term, key(CtrlU))
+	receive(term, "git")
+	await(t, prompt, func(e *Ed) bool { return string(e.Suggested) == " status" })
+}
@@ -3,8 +3,13 @@ package led
 import (
 	"bytes"
 	"github.com/pkg/term"
+	"os"
+	"strconv"
+	"time"
 )
 
+const flashTime = 100 * time.Millisecond
+
 // StartTerm starts a terminal.
 func StartTerm(ts ...Iterm) *Term {
 	t := Term{}
@@ -63,6 +68,23 @@ func (t *Term) Clear() {
 	t.Write(chars(Clear))
 }
 
+// ClearScreen clears the screen, and moves the cursor to the top left corner.
+func (t *Term) ClearScreen() {
+	t.Write(chars(ClearScreen))
+}
+
+// Bell rings the terminal bell.
+func (t *Term) Bell() {
+	t.Write(chars(Bell))
+}
+
+// Flash briefly flashes the screen by reversing its colors.
+func (t *Term) Flash() {
+	t.Write(chars(FlashOn))
+	time.Sleep(flashTime)
+	t.Write(chars(FlashOff))
+}
+
 // ShowCursor shows the cursor.
 func (t *Term) ShowCursor() {
 	t.Write(chars(ShowCursor))
@@ -101,6 +123,23 @@ func (t *Term) Stop() {
 	t.tty.Close()
 }
 
+// Size returns the number of columns and rows of the terminal. Ttys that
+// do not implement a Size method, or return zeros, fall back to the COLUMNS
+// and LINES environment variables, and default to 80 by 24.
+func (t *Term) Size() (int, int) {
+	cols, rows := 0, 0
+	if s, ok := t.tty.(sizer); ok {
+		cols, rows = s.Size()
+	}
+	if cols <= 0 {
+		cols = envInt("COLUMNS", 80)
+	}
+	if rows <= 0 {
+		rows = envInt("LINES", 24)
+	}
+	return cols, rows
+}
+
 // Iterm represents a subset of the tty implemented in github.com/pkg/term.
 type Iterm interface {
 	Start()
@@ -111,6 +150,11 @@ type Iterm interface {
 	Close() error
 }
 
+// sizer is implemented by ttys that know their size.
+type sizer interface {
+	Size() (int, int)
+}
+
 type termWrap struct {
 	tty *term.Term
 }
@@ -141,6 +185,13 @@ func (t *termWrap) RawMode() error {
 	return term.RawMode(t.tty)
 }
 
+func envInt(name string, def int) int {
+	if i, err := strconv.Atoi(os.Getenv(name)); err == nil && i > 0 {
+		return i
+	}
+	return def
+}
+
 func chars(c int) []byte {
 	return Ansi(c)
 }
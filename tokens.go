@@ -0,0 +1,144 @@
+package led
+
+import (
+	"strings"
+)
+
+// Token represents a word in a line, as split by Tokenize.
+type Token struct {
+	// Word is the word with quotes and escapes removed.
+	Word string
+	// Start and End are the range of the token in the line, including
+	// quotes and escapes.
+	Start int
+	End   int
+	// Quote is the quote char if the token ends in an open quote.
+	Quote byte
+}
+
+// Tokenize splits the given line into words like shells do. Words are
+// separated by spaces, tabs, and newlines outside of quotes, and by the
+// operators `;`, `&`, `|`, `<`, `>`, `(`, and `)`, a run of which forms a
+// token of its own, e.g. `&&`. Backslashes escape any char outside of
+// quotes, and `"`, `\`, `$`, and "`" inside of double quotes.
+func Tokenize(line []byte) []Token {
+	toks := []Token{}
+	var t *Token
+	w := []byte{}
+	escaped, op := false, false
+	for i, c := range line {
+		if t != nil && t.Quote == 0 && !escaped && !isBlank(c) && isOperator(c) != op {
+			t.End, t.Word, t = i, string(w), nil
+		}
+		if t == nil && !isBlank(c) {
+			toks = append(toks, Token{Start: i})
+			t = &toks[len(toks)-1]
+			w = w[:0]
+			op = isOperator(c)
+		}
+		switch {
+		case t == nil:
+			continue
+		case op && isOperator(c):
+			w = append(w, c)
+		case escaped:
+			if t.Quote == '"' && strings.IndexByte("\"\\$`", c) == -1 {
+				w = append(w, '\\')
+			}
+			w = append(w, c)
+			escaped = false
+		case c == '\\' && t.Quote != '\'':
+			escaped = true
+		case t.Quote != 0 && c == t.Quote:
+			t.Quote = 0
+		case t.Quote != 0:
+			w = append(w, c)
+		case c == '"' || c == '\'':
+			t.Quote = c
+		case isBlank(c):
+			t.End, t.Word, t = i, string(w), nil
+			continue
+		default:
+			w = append(w, c)
+		}
+	}
+	if t != nil {
+		t.End, t.Word = len(line), string(w)
+	}
+	return toks
+}
+
+// firstToken returns the first token in the given line, or an empty token.
+func firstToken(line []byte) Token {
+	if toks := Tokenize(line); len(toks) > 0 {
+		return toks[0]
+	}
+	return Token{}
+}
+
+// lastToken returns the token at the end of the given line, or an empty
+// token at the end if the line is empty or ends in a blank or an operator.
+func lastToken(line []byte) Token {
+	toks := Tokenize(line)
+	if n := len(toks); n > 0 && toks[n-1].End == len(line) && !isOperator(line[toks[n-1].Start]) {
+		return toks[n-1]
+	}
+	return Token{Start: len(line), End: len(line)}
+}
+
+// tokenAt returns the token under or before the given position in the line.
+// The word only holds the part left of the position, while the range covers
+// the whole token. If the position is at the start of a token, or after a
+// blank, an empty token at the position is returned.
+func tokenAt(line []byte, pos int) Token {
+	t := lastToken(line[:pos])
+	if t.Start == pos {
+		return t
+	}
+	for _, tok := range Tokenize(line) {
+		if tok.Start == t.Start {
+			t.End = tok.End
+		}
+	}
+	return t
+}
+
+// quoteWord quotes the given word for the given quote char, or escapes
+// special chars with backslashes if there is none, so it can be inserted
+// into a line for a token with that quote.
+func quoteWord(word string, quote byte) string {
+	b := []byte{}
+	if quote != 0 {
+		b = append(b, quote)
+	}
+	for _, c := range []byte(word) {
+		switch {
+		case quote == '\'' && c == '\'':
+			b = append(b, `'\''`...)
+			continue
+		case quote == '"' && strings.IndexByte("\"\\$`", c) != -1:
+			b = append(b, '\\')
+		case quote == 0 && strings.IndexByte(" \t\n\\'\"$`&|;<>()*?[]#!{}", c) != -1:
+			b = append(b, '\\')
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// closeQuote returns the suffix that ends a word quoted with the given quote
+// char, followed by a space.
+func closeQuote(quote byte) string {
+	if quote == 0 {
+		return " "
+	}
+	return string(quote) + " "
+}
+
+func isOperator(c byte) bool {
+	return strings.IndexByte(";&|<>()", c) != -1
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
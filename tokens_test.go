@@ -0,0 +1,84 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	line := []byte(`cp  "my file"	my\ other\ file 'it''s' "a\b\"c`)
+	assert.Equal(t, []Token{
+		{Word: "cp", Start: 0, End: 2},
+		{Word: "my file", Start: 4, End: 13},
+		{Word: "my other file", Start: 14, End: 29},
+		{Word: "its", Start: 30, End: 37},
+		{Word: `a\b"c`, Start: 38, End: 45, Quote: '"'},
+	}, Tokenize(line))
+	assert.Equal(t, []Token{}, Tokenize([]byte("  ")))
+
+	assert.Equal(t, []Token{
+		{Word: "ls", Start: 0, End: 2},
+		{Word: ";", Start: 2, End: 3},
+		{Word: "cat", Start: 3, End: 6},
+		{Word: "a;b", Start: 7, End: 11},
+		{Word: "&&", Start: 11, End: 13},
+		{Word: "x|y", Start: 14, End: 19},
+		{Word: "|", Start: 19, End: 20},
+	}, Tokenize([]byte(`ls;cat a\;b&& "x|y"|`)))
+}
+
+func TestLastToken(t *testing.T) {
+	assert.Equal(t, Token{Word: "my fi", Start: 3, End: 9, Quote: '\''}, lastToken([]byte("vi 'my fi")))
+	assert.Equal(t, Token{Start: 7, End: 7}, lastToken([]byte("vi foo ")))
+	assert.Equal(t, Token{Start: 2, End: 2}, lastToken([]byte("a|")))
+}
+
+func TestQuoteWord(t *testing.T) {
+	assert.Equal(t, `my\ file\$`, quoteWord("my file$", 0))
+	assert.Equal(t, `"my \"file\$`, quoteWord(`my "file$`, '"'))
+	assert.Equal(t, `'it'\''s`, quoteWord("it's", '\''))
+}
+
+func TestBackWordQuoted(t *testing.T) {
+	prompt, term := setup()
+	receive(term, `cp "my file"`)
+	receive(term, key(CtrlW))
+	assert.Equal(t, "cp ", prompt.Str())
+
+	receive(term, "foo\t \t")
+	receive(term, key(CtrlW))
+	assert.Equal(t, "cp ", prompt.Str())
+}
+
+func TestCompleteQuoted(t *testing.T) {
+	prompt, term := setup()
+	prompt.Completer = WordCompleter(bytesOf("my file", "other"))
+	receive(term, "cat  my")
+	receive(term, key(Tab))
+	assert.Equal(t, `cat  my\ file `, prompt.Str())
+
+	prompt, term = setup()
+	prompt.Completer = WordCompleter(bytesOf("my file", "other"))
+	receive(term, `cat "my`)
+	receive(term, key(Tab))
+	assert.Equal(t, `cat "my file" `, prompt.Str())
+
+	prompt, term = setup()
+	receive(term, "cat  my")
+	prompt.CompleteNext(bytesOf("my file"))
+	assert.Equal(t, `cat  my\ file`, prompt.Str())
+}
+
+func TestCompleteAfterOperator(t *testing.T) {
+	prompt, term := setup()
+	prompt.Completer = WordCompleter(bytesOf("foo", "grep"))
+	receive(term, "ls;cat fo")
+	receive(term, key(Tab))
+	assert.Equal(t, "ls;cat foo ", prompt.Str())
+
+	prompt, term = setup()
+	prompt.Completer = WordCompleter(bytesOf("foo", "grep"))
+	receive(term, "a|gr")
+	receive(term, key(Tab))
+	assert.Equal(t, "a|grep ", prompt.Str())
+}
@@ -0,0 +1,296 @@
+package led
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlParser parses the subset of TOML used by config files: tables, key
+// value pairs with bare, quoted, and dotted keys, basic and literal strings,
+// integers, booleans, and arrays.
+type tomlParser struct {
+	data   []byte
+	pos    int
+	line   int
+	root   map[string]interface{}
+	tables map[string]bool
+	lines  map[string]int
+}
+
+// parseTOML returns the values in the given TOML document, and the line
+// numbers of the keys by their path, see jsonLines.
+func parseTOML(data []byte) (map[string]interface{}, map[string]int, error) {
+	p := &tomlParser{data: data, line: 1, root: map[string]interface{}{}, tables: map[string]bool{}, lines: map[string]int{}}
+	table, prefix := p.root, ""
+	for {
+		p.skip(true)
+		if p.eof() {
+			return p.root, p.lines, nil
+		}
+		line := p.line
+		if p.peek() == '[' {
+			p.pos++
+			keys, err := p.keys()
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := p.expect(']'); err != nil {
+				return nil, nil, err
+			}
+			prefix = strings.Join(keys, ".")
+			if p.tables[prefix] {
+				return nil, nil, p.errorf("duplicate table: %s", prefix)
+			}
+			p.tables[prefix] = true
+			p.lines[prefix] = line
+			if table, err = p.table(p.root, "", keys); err != nil {
+				return nil, nil, err
+			}
+		} else if err := p.pair(table, prefix); err != nil {
+			return nil, nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// pair parses a key value pair into the given table.
+func (p *tomlParser) pair(table map[string]interface{}, prefix string) error {
+	line := p.line
+	keys, err := p.keys()
+	if err != nil {
+		return err
+	}
+	if err := p.expect('='); err != nil {
+		return err
+	}
+	t, err := p.table(table, prefix, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	path := joinPath(prefix, strings.Join(keys, "."))
+	key := keys[len(keys)-1]
+	if _, ok := t[key]; ok {
+		return p.errorf("duplicate key: %s", path)
+	}
+	p.lines[path] = line
+	t[key], err = p.value(path)
+	return err
+}
+
+// table returns the table with the given keys below the given table,
+// creating missing tables.
+func (p *tomlParser) table(t map[string]interface{}, prefix string, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		prefix = joinPath(prefix, key)
+		switch v := t[key].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			t[key], t = next, next
+		case map[string]interface{}:
+			t = v
+		default:
+			return nil, p.errorf("not a table: %s", prefix)
+		}
+	}
+	return t, nil
+}
+
+// keys parses a dotted key.
+func (p *tomlParser) keys() ([]string, error) {
+	keys := []string{}
+	for {
+		p.skip(false)
+		var key string
+		var err error
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			key, err = p.str()
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLKeyChar(p.peek()) {
+				p.pos++
+			}
+			key = string(p.data[start:p.pos])
+			if key == "" {
+				err = p.errorf("invalid key")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skip(false)
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// value parses the value of the setting with the given path.
+func (p *tomlParser) value(path string) (interface{}, error) {
+	p.skip(false)
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.str()
+	case c == '[':
+		p.pos++
+		values := []interface{}{}
+		for {
+			p.skip(true)
+			if p.peek() == ']' {
+				p.pos++
+				return values, nil
+			}
+			elem := joinPath(path, strconv.Itoa(len(values)))
+			p.lines[elem] = p.line
+			v, err := p.value(elem)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			p.skip(true)
+			if p.peek() == ',' {
+				p.pos++
+			} else if p.peek() != ']' {
+				return nil, p.errorf("expected , or ] in array")
+			}
+		}
+	}
+	start := p.pos
+	for !p.eof() && isTOMLKeyChar(p.peek()) || p.peek() == '+' {
+		p.pos++
+	}
+	word := string(p.data[start:p.pos])
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if i, err := strconv.ParseInt(strings.Replace(word, "_", "", -1), 10, 64); err == nil && word != "" {
+		return i, nil
+	}
+	return nil, p.errorf("invalid value: %s", word+p.rest())
+}
+
+// str parses a basic or literal string on a single line.
+func (p *tomlParser) str() (string, error) {
+	quote := p.peek()
+	p.pos++
+	b := []byte{}
+	for !p.eof() && p.peek() != quote && p.peek() != '\n' {
+		c := p.peek()
+		p.pos++
+		if quote == '\'' || c != '\\' {
+			b = append(b, c)
+			continue
+		}
+		if p.eof() || p.peek() == '\n' {
+			break
+		}
+		r, err := p.escape()
+		if err != nil {
+			return "", err
+		}
+		b = append(b, string(r)...)
+	}
+	if p.eof() || p.peek() != quote {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+	return string(b), nil
+}
+
+// tomlEscapes maps the chars following a backslash to the chars they stand
+// for.
+var tomlEscapes = map[byte]rune{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', '"': '"', '\\': '\\'}
+
+// escape parses the escape sequence following a backslash in a basic string.
+func (p *tomlParser) escape() (rune, error) {
+	c := p.peek()
+	p.pos++
+	if r, ok := tomlEscapes[c]; ok {
+		return r, nil
+	}
+	n := map[byte]int{'u': 4, 'U': 8}[c]
+	if n == 0 || p.pos+n > len(p.data) {
+		return 0, p.errorf("invalid escape: \\%c", c)
+	}
+	hex := string(p.data[p.pos : p.pos+n])
+	i, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(i)) {
+		return 0, p.errorf("invalid escape: \\%c%s", c, hex)
+	}
+	p.pos += n
+	return rune(i), nil
+}
+
+// skip skips blanks and comments, and newlines if given.
+func (p *tomlParser) skip(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+		case c == '\n' && newlines:
+			p.line++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+			continue
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// endOfLine skips the rest of the line, which must be blank or a comment.
+func (p *tomlParser) endOfLine() error {
+	p.skip(false)
+	if !p.eof() && p.peek() != '\n' {
+		return p.errorf("unexpected %s", p.rest())
+	}
+	return nil
+}
+
+func (p *tomlParser) expect(c byte) error {
+	p.skip(false)
+	if p.peek() != c {
+		return p.errorf("expected %c", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// rest returns the rest of the current line.
+func (p *tomlParser) rest() string {
+	end := p.pos
+	for end < len(p.data) && p.data[end] != '\n' {
+		end++
+	}
+	return strings.TrimSpace(string(p.data[p.pos:end]))
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func isTOMLKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
@@ -0,0 +1,48 @@
+package led
+
+import (
+	"bytes"
+)
+
+// snapshot represents the state of the line at some point, used for undo and
+// redo.
+type snapshot struct {
+	chars []byte
+	pos   int
+}
+
+// Undo reverts the last change to the line.
+func (e *Ed) Undo() {
+	if len(e.undos) == 0 {
+		return
+	}
+	s := e.undos[len(e.undos)-1]
+	e.undos = e.undos[:len(e.undos)-1]
+	e.redos = append(e.redos, e.snapshot())
+	e.redraw(dup(s.chars), s.pos)
+}
+
+// Redo reapplies the last change reverted by Undo.
+func (e *Ed) Redo() {
+	if len(e.redos) == 0 {
+		return
+	}
+	s := e.redos[len(e.redos)-1]
+	e.redos = e.redos[:len(e.redos)-1]
+	e.undos = append(e.undos, e.snapshot())
+	e.redraw(dup(s.chars), s.pos)
+}
+
+// save records the current state of the line before it is changed, unless it
+// equals the most recently recorded state.
+func (e *Ed) save() {
+	e.redos = nil
+	if len(e.undos) > 0 && bytes.Equal(e.undos[len(e.undos)-1].chars, e.Chars) {
+		return
+	}
+	e.undos = append(e.undos, e.snapshot())
+}
+
+func (e *Ed) snapshot() snapshot {
+	return snapshot{chars: dup(e.Chars), pos: e.Pos}
+}
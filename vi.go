@@ -0,0 +1,662 @@
+package led
+
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Results of parsing a vi command.
+const (
+	viPending = iota
+	viInvalid
+	viMotion
+	viChange
+)
+
+// NewViReadline creates a line editor with a vi keymap. The editor starts
+// each line in insert mode, Esc switches to normal mode.
+//
+// Normal mode supports the motions h l w b e W B E 0 ^ $ f F t T ; , the
+// operators d c y combined with motions, text objects (iw aw iW aW i" a" i'
+// a' i( a( i[ a[ i{ a{ i< a<), or themselves (dd cc yy), counts, and the
+// commands i a I A x X r ~ s S D C Y p P u Ctrl-R j k and `.`.
+func NewViReadline(led string, t ...Iterm) *Ed {
+	e := NewEd(led, t...)
+	bindVi(e)
+	return e
+}
+
+func bindVi(e *Ed) {
+	e.vi = &vi{insert: true}
+	e.HandleAction(Chars, "vi-self-insert")
+	e.HandleAction(Esc, "vi-movement-mode")
+	e.HandleAction(CtrlC, "discard-line")
+	e.HandleAction(CtrlR, "redo")
+	e.HandleAction(Enter, "newline")
+	e.HandleAction(Tab, "complete")
+	e.HandleAction(ShiftTab, "complete-backward")
+	e.HandleAction(Backspace, "vi-backward-delete-char")
+	e.HandleAction(CtrlH, "vi-backward-delete-char")
+	e.HandleAction(Delete, "vi-delete-char")
+	e.HandleAction(CtrlU, "vi-unix-line-discard")
+	e.HandleAction(CtrlW, "vi-unix-word-rubout")
+	e.HandleAction(Left, "backward-char")
+	e.HandleAction(Right, "vi-forward-char")
+	e.HandleAction(Up, "previous-history")
+	e.HandleAction(Down, "next-history")
+}
+
+// vi holds the state of the vi keymap.
+type vi struct {
+	insert    bool
+	keys      []byte
+	change    []Key
+	recording bool
+	replaying bool
+	find      []byte
+	reg       []byte
+}
+
+func (v *vi) reset() {
+	v.insert = true
+	v.keys = nil
+	v.recording = false
+}
+
+// record wraps the given handler so the key is recorded as part of the last
+// change while in insert mode.
+func (v *vi) record(h func(*Ed, Key)) func(*Ed, Key) {
+	return func(e *Ed, k Key) {
+		if v.insert && v.recording && !v.replaying {
+			v.change = append(v.change, k)
+		}
+		h(e, k)
+	}
+}
+
+func (v *vi) chars(e *Ed, k Key) {
+	if v.insert {
+		v.record(func(e *Ed, k Key) { e.Insert(k.Chars) })(e, k)
+		return
+	}
+	for i, c := range k.Chars {
+		if v.insert {
+			v.chars(e, Key{Code: Chars, Chars: k.Chars[i:]})
+			return
+		}
+		v.keys = append(v.keys, c)
+		v.run(e)
+	}
+}
+
+func (v *vi) esc(e *Ed, k Key) {
+	if !v.insert {
+		v.keys = nil
+		return
+	}
+	if v.recording && !v.replaying {
+		v.change = append(v.change, k)
+	}
+	v.normal(e)
+}
+
+func (v *vi) back(e *Ed) {
+	if v.insert {
+		e.Back()
+	} else {
+		e.Left()
+	}
+}
+
+// run executes the pending command, if complete.
+func (v *vi) run(e *Ed) {
+	keys := v.keys
+	r := v.exec(e, keys)
+	if r == viPending {
+		return
+	}
+	v.keys = nil
+	if r == viChange && !v.replaying {
+		v.change = charKeys(keys)
+		v.recording = v.insert
+	}
+}
+
+func (v *vi) exec(e *Ed, b []byte) int {
+	n, given, b := viCount(b)
+	if len(b) == 0 {
+		return viPending
+	}
+	l, pos := len(e.Chars), e.Pos
+
+	switch c := b[0]; c {
+	case 'i':
+		e.save()
+		v.enter(e, pos)
+	case 'a':
+		e.save()
+		v.enter(e, min(pos+1, l))
+	case 'I':
+		e.save()
+		v.enter(e, firstNonBlank(e.Chars))
+	case 'A':
+		e.save()
+		v.enter(e, l)
+	case 'x':
+		return v.operate(e, 'd', pos, min(pos+n, l))
+	case 'X':
+		return v.operate(e, 'd', max(pos-n, 0), pos)
+	case 's':
+		return v.operate(e, 'c', pos, min(pos+n, l))
+	case 'S':
+		return v.operate(e, 'c', 0, l)
+	case 'D':
+		return v.operate(e, 'd', pos, l)
+	case 'C':
+		return v.operate(e, 'c', pos, l)
+	case 'Y':
+		return v.operate(e, 'y', 0, l)
+	case 'r':
+		if len(b) < 2 {
+			return viPending
+		}
+		return v.replace(e, n, b[1])
+	case '~':
+		return v.toggleCase(e, n)
+	case 'p', 'P':
+		return v.paste(e, n, c == 'p')
+	case 'u':
+		for i := 0; i < n; i++ {
+			e.Undo()
+		}
+		v.cursor(e, e.Pos)
+		return viMotion
+	case '.':
+		return v.repeat(e, n, given)
+	case 'j':
+		e.HistoryNext(e.Hist.Lines())
+		v.cursor(e, e.Pos)
+		return viMotion
+	case 'k':
+		e.HistoryPrev(e.Hist.Lines())
+		v.cursor(e, e.Pos)
+		return viMotion
+	case 'd', 'c', 'y':
+		return v.execOp(e, c, n, b[1:])
+	default:
+		to, _, r := v.motion(e, b, n, 0)
+		if r == viMotion {
+			v.cursor(e, to)
+		}
+		return r
+	}
+	return viChange
+}
+
+// execOp executes the given operator with the motion or text object in b.
+func (v *vi) execOp(e *Ed, op byte, n int, b []byte) int {
+	m, _, b := viCount(b)
+	if len(b) == 0 {
+		return viPending
+	}
+	n = n * m
+
+	switch {
+	case b[0] == op:
+		return v.operate(e, op, 0, len(e.Chars))
+	case b[0] == 'i' || b[0] == 'a':
+		if len(b) < 2 {
+			return viPending
+		}
+		from, to, ok := textObject(e.Chars, e.Pos, b[0] == 'i', b[1])
+		if !ok {
+			return viInvalid
+		}
+		return v.operate(e, op, from, to)
+	}
+
+	to, incl, r := v.motion(e, b, n, op)
+	if r != viMotion {
+		return r
+	}
+	from := e.Pos
+	if to < from {
+		from, to = to, from
+	} else if incl {
+		to++
+	}
+	return v.operate(e, op, from, min(to, len(e.Chars)))
+}
+
+// motion returns the target position of the motion in b, and whether the
+// target char is included when used with an operator.
+func (v *vi) motion(e *Ed, b []byte, n int, op byte) (int, bool, int) {
+	s, pos := e.Chars, e.Pos
+	switch c := b[0]; c {
+	case 'h':
+		return max(pos-n, 0), false, viMotion
+	case 'l', ' ':
+		return min(pos+n, len(s)), false, viMotion
+	case '0':
+		return 0, false, viMotion
+	case '^':
+		return firstNonBlank(s), false, viMotion
+	case '$':
+		return max(len(s)-1, 0), true, viMotion
+	case 'w', 'W':
+		big := c == 'W'
+		if op == 'c' && pos < len(s) && !isSpace(s[pos]) {
+			// cw behaves like ce
+			return repeatMotion(s, pos, n, big, wordEnd), true, viMotion
+		}
+		return repeatMotion(s, pos, n, big, nextWordStart), false, viMotion
+	case 'b', 'B':
+		return repeatMotion(s, pos, n, c == 'B', prevWordStart), false, viMotion
+	case 'e', 'E':
+		return repeatMotion(s, pos, n, c == 'E', wordEnd), true, viMotion
+	case 'f', 'F', 't', 'T':
+		if len(b) < 2 {
+			return 0, false, viPending
+		}
+		v.find = []byte{c, b[1]}
+		return findChar(s, pos, n, c, b[1])
+	case ';', ',':
+		if v.find == nil {
+			return 0, false, viInvalid
+		}
+		c := v.find[0]
+		if b[0] == ',' {
+			c = reverseFind(c)
+		}
+		return findChar(s, pos, n, c, v.find[1])
+	}
+	return 0, false, viInvalid
+}
+
+// operate applies the given operator (d, c, or y) to the given range.
+func (v *vi) operate(e *Ed, op byte, from int, to int) int {
+	if from >= to && op != 'c' {
+		return viInvalid
+	}
+	v.reg = dup(e.Chars[from:to])
+	if op == 'y' {
+		v.cursor(e, from)
+		return viMotion
+	}
+
+	e.save()
+	e.redraw(concat(dup(e.Chars[:from]), e.Chars[to:]), from)
+	if op == 'c' {
+		v.enter(e, from)
+	} else {
+		v.cursor(e, from)
+	}
+	return viChange
+}
+
+func (v *vi) replace(e *Ed, n int, c byte) int {
+	if e.Pos+n > len(e.Chars) {
+		return viInvalid
+	}
+	b := dup(e.Chars)
+	for i := e.Pos; i < e.Pos+n; i++ {
+		b[i] = c
+	}
+	e.save()
+	e.redraw(b, e.Pos+n-1)
+	return viChange
+}
+
+func (v *vi) toggleCase(e *Ed, n int) int {
+	if len(e.Chars) == 0 {
+		return viInvalid
+	}
+	b := dup(e.Chars[:e.Pos])
+	i := e.Pos
+	for ; n > 0 && i < len(e.Chars); n-- {
+		r, size := utf8.DecodeRune(e.Chars[i:])
+		switch {
+		case r == utf8.RuneError:
+			b = append(b, e.Chars[i:i+size]...)
+		case unicode.IsLower(r):
+			b = append(b, string(unicode.ToUpper(r))...)
+		default:
+			b = append(b, string(unicode.ToLower(r))...)
+		}
+		i += size
+	}
+	to := len(b)
+	e.save()
+	e.redraw(concat(b, e.Chars[i:]), e.Pos)
+	v.cursor(e, to)
+	return viChange
+}
+
+func (v *vi) paste(e *Ed, n int, after bool) int {
+	if len(v.reg) == 0 {
+		return viInvalid
+	}
+	pos := e.Pos
+	if after && len(e.Chars) > 0 {
+		pos = min(pos+1, len(e.Chars))
+	}
+	p := bytes.Repeat(v.reg, n)
+	e.save()
+	e.redraw(concat(dup(e.Chars[:pos]), p, e.Chars[pos:]), pos+len(p)-1)
+	return viChange
+}
+
+// repeat replays the last change, using the given count, if any.
+func (v *vi) repeat(e *Ed, n int, given bool) int {
+	if v.change == nil {
+		return viInvalid
+	}
+	keys := v.change
+	if given {
+		i := 0
+		for i < len(keys) && keys[i].Code == Chars && isDigit(keys[i].Chars[0]) {
+			i++
+		}
+		keys = append(charKeys([]byte(strconv.Itoa(n))), keys[i:]...)
+	}
+
+	v.replaying = true
+	v.keys = nil
+	for _, k := range keys {
+		if h := e.handlers[k.Code]; h != nil {
+			h(e, k)
+		}
+	}
+	if v.insert {
+		v.normal(e)
+	}
+	v.replaying = false
+	return viMotion
+}
+
+// enter switches to insert mode at the given position. Chars typed in insert
+// mode are undone in one step with the command that entered insert mode.
+func (v *vi) enter(e *Ed, pos int) {
+	e.SetCursor(pos)
+	e.mark(cmdInsert)
+	v.insert = true
+}
+
+// normal switches to normal mode, and moves the cursor one char to the left.
+func (v *vi) normal(e *Ed) {
+	v.insert = false
+	v.recording = false
+	v.cursor(e, e.Pos-1)
+}
+
+func (v *vi) right(e *Ed, n int) {
+	if v.insert {
+		e.Right()
+	} else {
+		v.cursor(e, e.Pos+n)
+	}
+}
+
+// cursor sets the cursor to the given position, keeping it on the last char
+// in normal mode.
+func (v *vi) cursor(e *Ed, pos int) {
+	if !v.insert && pos >= len(e.Chars) {
+		pos = len(e.Chars) - 1
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	if pos != e.Pos {
+		e.SetCursor(pos)
+	}
+}
+
+// viCount parses a count at the beginning of the given command, and returns
+// it (defaulting to 1), whether it was given, and the rest of the command.
+func viCount(b []byte) (int, bool, []byte) {
+	i := 0
+	for i < len(b) && isDigit(b[i]) && !(i == 0 && b[i] == '0') {
+		i++
+	}
+	if i == 0 {
+		return 1, false, b
+	}
+	n, _ := strconv.Atoi(string(b[:i]))
+	return n, true, b[i:]
+}
+
+func charKeys(b []byte) []Key {
+	keys := []Key{}
+	for _, c := range b {
+		keys = append(keys, Key{Code: Chars, Chars: []byte{c}})
+	}
+	return keys
+}
+
+func repeatMotion(s []byte, pos int, n int, big bool, f func([]byte, int, bool) int) int {
+	for i := 0; i < n; i++ {
+		pos = f(s, pos, big)
+	}
+	return pos
+}
+
+func findChar(s []byte, pos int, n int, cmd byte, c byte) (int, bool, int) {
+	i := pos
+	for ; n > 0; n-- {
+		if cmd == 'f' || cmd == 't' {
+			j := bytes.IndexByte(s[min(i+1, len(s)):], c)
+			if j < 0 {
+				return 0, false, viInvalid
+			}
+			i = i + 1 + j
+		} else {
+			j := bytes.LastIndexByte(s[:i], c)
+			if j < 0 {
+				return 0, false, viInvalid
+			}
+			i = j
+		}
+	}
+	switch cmd {
+	case 't':
+		i--
+	case 'T':
+		i++
+	}
+	return i, cmd == 'f' || cmd == 't', viMotion
+}
+
+func reverseFind(c byte) byte {
+	return map[byte]byte{'f': 'F', 'F': 'f', 't': 'T', 'T': 't'}[c]
+}
+
+// textObject returns the range of the given text object (w, W, a quote, or a
+// bracket) around the given position.
+func textObject(s []byte, pos int, inner bool, c byte) (int, int, bool) {
+	if pos >= len(s) {
+		return 0, 0, false
+	}
+	switch c {
+	case 'w', 'W':
+		from, to := wordAt(s, pos, c == 'W')
+		if !inner {
+			if to < len(s) && isSpace(s[to]) {
+				_, to = wordAt(s, to, c == 'W')
+			} else if from > 0 && isSpace(s[from-1]) {
+				from, _ = wordAt(s, from-1, c == 'W')
+			}
+		}
+		return from, to, true
+	case '"', '\'', '`':
+		from, to, ok := quotesAt(s, pos, c)
+		if !ok {
+			return 0, 0, false
+		}
+		if inner {
+			return from + 1, to, true
+		}
+		return from, to + 1, true
+	}
+
+	open, close := brackets(c)
+	if open == 0 {
+		return 0, 0, false
+	}
+	from, to := -1, -1
+	for i, d := pos, 0; i >= 0; i-- {
+		if s[i] == close && i != pos {
+			d++
+		} else if s[i] == open {
+			if d == 0 {
+				from = i
+				break
+			}
+			d--
+		}
+	}
+	for i, d := max(from+1, pos), 0; from >= 0 && i < len(s); i++ {
+		if s[i] == open {
+			d++
+		} else if s[i] == close {
+			if d == 0 {
+				to = i
+				break
+			}
+			d--
+		}
+	}
+	if from < 0 || to < 0 {
+		return 0, 0, false
+	}
+	if inner {
+		return from + 1, to, true
+	}
+	return from, to + 1, true
+}
+
+func brackets(c byte) (byte, byte) {
+	switch c {
+	case '(', ')', 'b':
+		return '(', ')'
+	case '[', ']':
+		return '[', ']'
+	case '{', '}', 'B':
+		return '{', '}'
+	case '<', '>':
+		return '<', '>'
+	}
+	return 0, 0
+}
+
+// wordAt returns the range of the word, or whitespace, at the given position.
+func wordAt(s []byte, pos int, big bool) (int, int) {
+	c := class(s[pos], big)
+	from, to := pos, pos
+	for from > 0 && class(s[from-1], big) == c {
+		from--
+	}
+	for to < len(s) && class(s[to], big) == c {
+		to++
+	}
+	return from, to
+}
+
+func nextWordStart(s []byte, pos int, big bool) int {
+	i := pos
+	if i >= len(s) {
+		return len(s)
+	}
+	if c := class(s[i], big); c != 0 {
+		for i < len(s) && class(s[i], big) == c {
+			i++
+		}
+	}
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func prevWordStart(s []byte, pos int, big bool) int {
+	i := pos
+	if i > 0 {
+		i--
+	}
+	for i > 0 && isSpace(s[i]) {
+		i--
+	}
+	if i < len(s) {
+		c := class(s[i], big)
+		for i > 0 && class(s[i-1], big) == c {
+			i--
+		}
+	}
+	return i
+}
+
+func wordEnd(s []byte, pos int, big bool) int {
+	i := pos + 1
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	if i >= len(s) {
+		return max(len(s)-1, 0)
+	}
+	c := class(s[i], big)
+	for i+1 < len(s) && class(s[i+1], big) == c {
+		i++
+	}
+	return i
+}
+
+func firstNonBlank(s []byte) int {
+	i := 0
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+// class returns the class of the given char: 0 for whitespace, 1 for word
+// chars, 2 for other chars. If big is true all non-whitespace chars are word
+// chars.
+func class(c byte, big bool) int {
+	switch {
+	case isSpace(c):
+		return 0
+	case big || isWordChar(c):
+		return 1
+	}
+	return 2
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// quotesAt returns the positions of the pair of the given quote char around
+// the given position, or else the first pair after it. Quotes are paired
+// from the beginning of the line.
+func quotesAt(s []byte, pos int, c byte) (int, int, bool) {
+	for from := 0; ; {
+		i := bytes.IndexByte(s[from:], c)
+		if i < 0 {
+			return 0, 0, false
+		}
+		j := bytes.IndexByte(s[from+i+1:], c)
+		if j < 0 {
+			return 0, 0, false
+		}
+		open, close := from+i, from+i+1+j
+		if close >= pos {
+			return open, close, true
+		}
+		from = close + 1
+	}
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,191 @@
+package led
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestViInsertAndNormal(t *testing.T) {
+	prompt, term := setupVi()
+	receive(term, "foo bar")
+	receive(term, key(Esc))
+	assert.Equal(t, 6, prompt.Pos)
+
+	typeKeys(term, "0")
+	assert.Equal(t, 0, prompt.Pos)
+
+	typeKeys(term, "Abaz")
+	assert.Equal(t, "foo barbaz", prompt.Str())
+}
+
+func TestViMotions(t *testing.T) {
+	prompt, term := setupVi()
+	receive(term, "foo.bar baz qux")
+	receive(term, key(Esc))
+
+	tests := []struct {
+		keys string
+		pos  int
+	}{
+		{"0", 0},
+		{"w", 3},
+		{"w", 4},
+		{"W", 8},
+		{"e", 10},
+		{"b", 8},
+		{"2B", 0},
+		{"$", 14},
+		{"^", 0},
+		{"fa", 5},
+		{";", 9},
+		{",", 5},
+		{"tz", 9},
+		{"Fo", 2},
+		{"2l", 4},
+		{"h", 3},
+	}
+	for _, test := range tests {
+		typeKeys(term, test.keys)
+		assert.Equal(t, test.pos, prompt.Pos, test.keys)
+	}
+}
+
+func TestViOperators(t *testing.T) {
+	tests := []struct {
+		keys  string
+		chars string
+		pos   int
+	}{
+		{"0dw", "bar baz", 0},
+		{"0d2w", "baz", 0},
+		{"02dw", "baz", 0},
+		{"0de", " bar baz", 0},
+		{"d0", "z", 0},
+		{"0d$", "", 0},
+		{"0D", "", 0},
+		{"dd", "", 0},
+		{"0x", "oo bar baz", 0},
+		{"03x", " bar baz", 0},
+		{"X", "foo bar bz", 9},
+		{"0dfb", "ar baz", 0},
+		{"0dtb", "bar baz", 0},
+		{"0wdiw", "foo  baz", 4},
+		{"0wdaw", "foo baz", 4},
+		{"0rx", "xoo bar baz", 0},
+		{"02~", "FOo bar baz", 2},
+		{"0ywP", "foo foo bar baz", 3},
+		{"0ywwP", "foo foo bar baz", 7},
+		{"0dwwp", "bar bfoo az", 8},
+	}
+	for _, test := range tests {
+		prompt, term := setupVi()
+		receive(term, "foo bar baz")
+		receive(term, key(Esc))
+		typeKeys(term, test.keys)
+		assert.Equal(t, test.chars, prompt.Str(), test.keys)
+		assert.Equal(t, test.pos, prompt.Pos, test.keys)
+	}
+}
+
+func TestViTextObjects(t *testing.T) {
+	tests := []struct {
+		keys  string
+		chars string
+	}{
+		{"0fadi\"", `echo "" (b (c)) 'd'`},
+		{"0fada\"", `echo  (b (c)) 'd'`},
+		{"0fbdi(", `echo "a" () 'd'`},
+		{"0f(;da(", `echo "a" (b ) 'd'`},
+		{"0f(;dab", `echo "a" (b ) 'd'`},
+		{"0fcdi(", `echo "a" (b (c)) 'd'`},
+		{"0fddi'", `echo "a" (b (c)) ''`},
+		{"0f\";di\"", `echo "" (b (c)) 'd'`},
+		{"0f'di'", `echo "a" (b (c)) ''`},
+	}
+	for _, test := range tests {
+		prompt, term := setupVi()
+		receive(term, `echo "a" `)
+		receive(term, `(b (c)) 'd'`)
+		receive(term, key(Esc))
+		typeKeys(term, test.keys)
+		assert.Equal(t, test.chars, prompt.Str(), test.keys)
+	}
+}
+
+func TestViToggleCaseUnicode(t *testing.T) {
+	prompt, term := setupVi()
+	receive(term, "üıe x")
+	receive(term, key(Esc))
+	typeKeys(term, "03~")
+	assert.Equal(t, "ÜIE x", prompt.Str())
+	assert.Equal(t, len("ÜIE"), prompt.Pos)
+}
+
+func TestViChange(t *testing.T) {
+	prompt, term := setupVi()
+	receive(term, "foo bar baz")
+	receive(term, key(Esc))
+	typeKeys(term, "0cwqux")
+	receive(term, key(Esc))
+	assert.Equal(t, "qux bar baz", prompt.Str())
+	assert.Equal(t, 2, prompt.Pos)
+
+	typeKeys(term, "w.")
+	assert.Equal(t, "qux qux baz", prompt.Str())
+
+	typeKeys(term, "u")
+	assert.Equal(t, "qux bar baz", prompt.Str())
+
+	typeKeys(term, "u")
+	assert.Equal(t, "foo bar baz", prompt.Str())
+
+	receive(term, key(CtrlR))
+	assert.Equal(t, "qux bar baz", prompt.Str())
+}
+
+func TestViRepeatWithCount(t *testing.T) {
+	prompt, term := setupVi()
+	receive(term, "abcdefgh")
+	receive(term, key(Esc))
+	typeKeys(term, "0x")
+	typeKeys(term, "3.")
+	assert.Equal(t, "efgh", prompt.Str())
+}
+
+func TestViResetStartsInInsertMode(t *testing.T) {
+	prompt, term := setupVi()
+	receive(term, "foo")
+	receive(term, key(Esc))
+	prompt.Reset()
+	receive(term, "x")
+	assert.Equal(t, "x", prompt.Str())
+}
+
+func TestViHistoryPaste(t *testing.T) {
+	prompt, term := setupVi()
+	prompt.Hist.Add([]byte("ab"))
+	receive(term, "a")
+	receive(term, key(Esc))
+	typeKeys(term, "yl")
+	typeKeys(term, "k")
+	assert.Equal(t, "ab", prompt.Str())
+	assert.Equal(t, 1, prompt.Pos)
+	typeKeys(term, "p")
+	assert.Equal(t, "aba", prompt.Str())
+}
+
+func setupVi() (*Ed, *testTerm) {
+	term := newTestTerm()
+	prompt := NewViReadline("t ~ ", term)
+	go prompt.Run()
+	time.Sleep(1 * time.Millisecond)
+	return prompt, term
+}
+
+// typeKeys sends the given chars as separate keys.
+func typeKeys(term *testTerm, keys string) {
+	for _, c := range keys {
+		receive(term, string(c))
+	}
+}
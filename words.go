@@ -0,0 +1,140 @@
+package led
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ForwardWord moves the cursor to the end of the next word. Words consist of
+// letters and digits, so punctuation like `/` or `-` separates words. Honours
+// the numeric argument (see Arg). At the end of the line it accepts the next
+// word of the suggestion, if any.
+func (e *Ed) ForwardWord() {
+	if !e.AcceptSuggestionWord() {
+		e.SetCursor(e.wordPos(e.Arg()))
+	}
+}
+
+// BackwardWord moves the cursor to the beginning of the previous word.
+func (e *Ed) BackwardWord() {
+	e.SetCursor(e.wordPos(-e.Arg()))
+}
+
+// KillWord deletes the chars from the cursor position to the end of the next
+// word, and adds them to the kill ring.
+func (e *Ed) KillWord() {
+	e.killTo(e.wordPos(e.Arg()))
+}
+
+// BackKillWord deletes the chars from the beginning of the previous word to
+// the cursor position, and adds them to the kill ring. Unlike BackWord it
+// stops at punctuation.
+func (e *Ed) BackKillWord() {
+	e.killTo(e.wordPos(-e.Arg()))
+}
+
+// UpcaseWord upcases the chars from the cursor position to the end of the
+// next word, and moves the cursor after them.
+func (e *Ed) UpcaseWord() {
+	e.changeWord(bytes.ToUpper)
+}
+
+// DowncaseWord downcases the chars from the cursor position to the end of
+// the next word, and moves the cursor after them.
+func (e *Ed) DowncaseWord() {
+	e.changeWord(bytes.ToLower)
+}
+
+// CapitalizeWord capitalizes the next word, and moves the cursor after it.
+func (e *Ed) CapitalizeWord() {
+	e.changeWord(func(b []byte) []byte {
+		b = bytes.ToLower(b)
+		for i, c := range b {
+			if isWordChar(c) {
+				r, n := utf8.DecodeRune(b[i:])
+				return concat(dup(b[:i]), []byte(string(unicode.ToUpper(r))), b[i+n:])
+			}
+		}
+		return b
+	})
+}
+
+// TransposeWords swaps the word before the cursor with the word after it, and
+// moves the cursor after both words. At the end of the line it swaps the last
+// two words.
+func (e *Ed) TransposeWords() {
+	s := e.Chars
+	w2end := forwardWord(s, e.Pos)
+	w2beg := backwardWord(s, w2end)
+	w1beg := backwardWord(s, w2beg)
+	w1end := forwardWord(s, w1beg)
+	if w1beg == w2beg || w2beg < w1end {
+		return
+	}
+	b := concat(dup(s[:w1beg]), s[w2beg:w2end], s[w1end:w2beg], s[w1beg:w1end], s[w2end:])
+	e.save()
+	e.redraw(b, w2end)
+}
+
+// wordPos returns the position after the given number of words following the
+// cursor position, or the beginning of the given number of words preceding it,
+// if negative.
+func (e *Ed) wordPos(n int) int {
+	pos := e.Pos
+	for ; n > 0; n-- {
+		pos = forwardWord(e.Chars, pos)
+	}
+	for ; n < 0; n++ {
+		pos = backwardWord(e.Chars, pos)
+	}
+	return pos
+}
+
+// killTo deletes the chars between the cursor and the given position, and
+// adds them to the kill ring.
+func (e *Ed) killTo(pos int) {
+	if pos == e.Pos {
+		return
+	}
+	e.save()
+	if pos > e.Pos {
+		e.kill(dup(e.Chars[e.Pos:pos]), Forw)
+		e.redraw(concat(dup(e.Chars[:e.Pos]), e.Chars[pos:]), e.Pos)
+	} else {
+		e.kill(dup(e.Chars[pos:e.Pos]), Back)
+		e.redraw(concat(dup(e.Chars[:pos]), e.Chars[e.Pos:]), pos)
+	}
+}
+
+// changeWord replaces the next word with the result of the given func, and
+// moves the cursor after it, which may have changed its length.
+func (e *Ed) changeWord(f func([]byte) []byte) {
+	to := forwardWord(e.Chars, e.Pos)
+	if to == e.Pos {
+		return
+	}
+	w := f(dup(e.Chars[e.Pos:to]))
+	e.save()
+	e.redraw(concat(dup(e.Chars[:e.Pos]), w, e.Chars[to:]), e.Pos+len(w))
+}
+
+func forwardWord(s []byte, pos int) int {
+	for pos < len(s) && !isWordChar(s[pos]) {
+		pos++
+	}
+	for pos < len(s) && isWordChar(s[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func backwardWord(s []byte, pos int) int {
+	for pos > 0 && !isWordChar(s[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordChar(s[pos-1]) {
+		pos--
+	}
+	return pos
+}